@@ -0,0 +1,165 @@
+package buildlog
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Parses work periods, e.g., 1pm-3:30pm, on the date of an existing log entry
+func ParseWorkPeriodsArgForLog(entry *protos.BuildLogEntry, arg string) ([]*protos.TimePeriod, error) {
+	date, err := ParseDateOfLog(entry)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWorkPeriodsArg(date.Year(), date.Month(), date.Day(), arg)
+}
+
+// Replaces the start and end of a work period, retaining whether work was paused after it
+func ReplaceWorkPeriod(entry *protos.BuildLogEntry, periodIndex int, wp *protos.TimePeriod) error {
+	if periodIndex < 0 || periodIndex >= len(entry.WorkPeriod) {
+		return fmt.Errorf("Log entry %s has no work period %d", LogEntryID(entry), periodIndex+1)
+	}
+	old := entry.WorkPeriod[periodIndex]
+	if IsOpenWorkPeriod(old) {
+		return fmt.Errorf("Work period %d of %s is ongoing, run 'stop' to end it first", periodIndex+1, LogEntryID(entry))
+	}
+	wp.Paused = old.Paused
+	wp.BreakNote = old.BreakNote
+	wp.Builder = old.Builder
+	wp.Helper = old.Helper
+	entry.WorkPeriod[periodIndex] = wp
+	return nil
+}
+
+func RemoveWorkPeriod(entry *protos.BuildLogEntry, periodIndex int) error {
+	if periodIndex < 0 || periodIndex >= len(entry.WorkPeriod) {
+		return fmt.Errorf("Log entry %s has no work period %d", LogEntryID(entry), periodIndex+1)
+	}
+	entry.WorkPeriod = append(entry.WorkPeriod[:periodIndex], entry.WorkPeriod[periodIndex+1:]...)
+	return nil
+}
+
+// Moves the timestamps of a work period to the same time of day on another date
+func moveWorkPeriod(entry *protos.BuildLogEntry, wp *protos.TimePeriod, date time.Time) error {
+	// Work periods recorded only as kitchen times are relative to the date of their log entry
+	if len(wp.Start) == 0 {
+		return nil
+	}
+	start, err := WorkPeriodStart(entry, wp)
+	if err != nil {
+		return err
+	}
+	moved := time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, start.Location())
+	wp.Start = moved.Format(time.RFC3339)
+	if !IsOpenWorkPeriod(wp) {
+		end, err := WorkPeriodEnd(entry, wp)
+		if err != nil {
+			return err
+		}
+		SetWorkPeriodEnd(wp, moved, moved.Add(end.Sub(start)))
+	}
+	return nil
+}
+
+// Moves a file within the logs dir from one path relative to the logs dir to another, creating its dir as needed
+func moveLogsFile(root string, from string, to string) error {
+	src := filepath.Join(LogsDir(root), filepath.FromSlash(from))
+	dst := filepath.Join(LogsDir(root), filepath.FromSlash(to))
+	if exists, err := FileExists(src); err != nil || !exists {
+		return err
+	}
+	if exists, err := FileExists(dst); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("Cannot move %s, %s already exists", from, to)
+	}
+	if err := EnsureDirExists(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+type logsFileMove struct {
+	from string
+	to   string
+}
+
+// Moves files within the logs dir, checking beforehand that no destination exists such that a conflict moves none of
+// them. Should a move fail regardless, the files already moved are moved back.
+func moveLogsFiles(root string, moves []logsFileMove) error {
+	dsts := map[string]bool{}
+	for _, m := range moves {
+		if dsts[m.to] {
+			return fmt.Errorf("Cannot move %s, another file is also moved to %s", m.from, m.to)
+		}
+		dsts[m.to] = true
+		if exists, err := FileExists(filepath.Join(LogsDir(root), filepath.FromSlash(m.to))); err != nil {
+			return err
+		} else if exists {
+			return fmt.Errorf("Cannot move %s, %s already exists", m.from, m.to)
+		}
+	}
+	for i, m := range moves {
+		if err := moveLogsFile(root, m.from, m.to); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				moveLogsFile(root, moves[j].to, moves[j].from)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Moves the log entry at logs[index] to another date, assigning it an ID for that date. Its details file and
+// attachments are moved to the dir for the new date unless shared with other log entries.
+func MoveLogEntry(root string, logs []*protos.BuildLogEntry, index int, date time.Time) error {
+	entry := logs[index]
+	for _, wp := range entry.WorkPeriod {
+		if err := moveWorkPeriod(entry, wp, date); err != nil {
+			return err
+		}
+	}
+	others := append(append([]*protos.BuildLogEntry{}, logs[:index]...), logs[index+1:]...)
+	id := NextLogEntryID(others, date)
+
+	shared := map[string]bool{}
+	for _, other := range others {
+		shared[LogDetailsRelativePath(other.DetailsFile)] = true
+		for _, a := range other.Attachment {
+			shared[a.File] = true
+		}
+	}
+	var moves []logsFileMove
+	newDetails := entry.DetailsFile
+	if len(entry.DetailsFile) > 0 && !shared[LogDetailsRelativePath(entry.DetailsFile)] {
+		newDetails = RelativeLogDetailsFile(date, id)
+		moves = append(moves, logsFileMove{LogDetailsRelativePath(entry.DetailsFile), newDetails})
+	}
+	movedAttachments := map[*protos.Attachment]string{}
+	for _, a := range entry.Attachment {
+		if shared[a.File] {
+			continue
+		}
+		moved := path.Join(LogDetailsDir(nil, date), path.Base(a.File))
+		if moved == a.File {
+			continue
+		}
+		moves = append(moves, logsFileMove{a.File, moved})
+		movedAttachments[a] = moved
+	}
+	if err := moveLogsFiles(root, moves); err != nil {
+		return err
+	}
+	entry.Date = FormatDateForLog(date)
+	entry.Id = id
+	entry.DetailsFile = newDetails
+	for a, moved := range movedAttachments {
+		a.File = moved
+	}
+	return nil
+}
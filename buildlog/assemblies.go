@@ -0,0 +1,106 @@
+package buildlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+type namedItem struct {
+	name    string
+	aliases []string
+}
+
+// Resolves arg to the canonical name of an item, matching case-insensitively against names and aliases, and then
+// against unambiguous prefixes of names and aliases
+func resolveName(items []namedItem, arg string) (string, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if len(arg) == 0 {
+		return "", false
+	}
+	for _, item := range items {
+		if strings.ToLower(item.name) == arg {
+			return item.name, true
+		}
+		for _, a := range item.aliases {
+			if strings.ToLower(a) == arg {
+				return item.name, true
+			}
+		}
+	}
+	match := ""
+	for _, item := range items {
+		for _, n := range append([]string{item.name}, item.aliases...) {
+			if strings.HasPrefix(strings.ToLower(n), arg) {
+				if len(match) > 0 && match != item.name {
+					return "", false
+				}
+				match = item.name
+			}
+		}
+	}
+	return match, len(match) > 0
+}
+
+func describeItems(items []namedItem) string {
+	var lines []string
+	for _, item := range items {
+		if len(item.aliases) > 0 {
+			lines = append(lines, fmt.Sprintf("%s (%s)", item.name, strings.Join(item.aliases, ", ")))
+		} else {
+			lines = append(lines, item.name)
+		}
+	}
+	return strings.Join(lines, "\n  ")
+}
+
+func assemblyItems(config *protos.ProjectConfig) []namedItem {
+	var items []namedItem
+	for _, a := range config.Assembly {
+		items = append(items, namedItem{a.Name, a.Alias})
+	}
+	return items
+}
+
+func FindAssembly(config *protos.ProjectConfig, name string) *protos.Assembly {
+	for _, a := range config.Assembly {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func ParseAssemblyArg(config *protos.ProjectConfig, arg string) (string, error) {
+	items := assemblyItems(config)
+	if name, ok := resolveName(items, arg); ok {
+		return name, nil
+	}
+	return "", fmt.Errorf("Assembly must be one of:\n  %s", describeItems(items))
+}
+
+// Parses a comma-separated list of subassemblies of the specified assembly. Subassemblies are free-form for
+// assemblies that do not configure any.
+func ParseSubassemblyArg(config *protos.ProjectConfig, assembly string, arg string) ([]string, error) {
+	var items []namedItem
+	if a := FindAssembly(config, assembly); a != nil {
+		for _, s := range a.Subassembly {
+			items = append(items, namedItem{s.Name, s.Alias})
+		}
+	}
+	var subassemblies []string
+	for _, v := range strings.Split(arg, ",") {
+		if len(strings.TrimSpace(v)) == 0 {
+			return nil, fmt.Errorf("Subassemblies must not be empty strings")
+		}
+		if len(items) == 0 {
+			subassemblies = append(subassemblies, strings.TrimSpace(v))
+		} else if name, ok := resolveName(items, v); ok {
+			subassemblies = append(subassemblies, name)
+		} else {
+			return nil, fmt.Errorf("Subassembly of %s must be one of:\n  %s", assembly, describeItems(items))
+		}
+	}
+	return subassemblies, nil
+}
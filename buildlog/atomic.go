@@ -0,0 +1,80 @@
+package buildlog
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Replaces the contents of f such that readers observe either the old or the new contents, never a partial write.
+// The permissions of an existing file are preserved.
+func WriteFileAtomic(f string, data []byte, perm os.FileMode) error {
+	if fi, err := os.Stat(f); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	dir := filepath.Dir(f)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(f)+".tmp*")
+	if err != nil {
+		return err
+	}
+	// Clean up the temp file on any failure prior to the rename
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// Flushes directory entries, e.g., a rename, to stable storage
+func syncDir(dir string) error {
+	fp, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if err := fp.Sync(); err != nil && !os.IsPermission(err) {
+		return err
+	}
+	return nil
+}
+
+func lockFilePath(f string) string {
+	return filepath.Join(filepath.Dir(f), "."+filepath.Base(f)+".lock")
+}
+
+// Acquires an exclusive advisory lock associated with f, blocking until it is available. The returned function
+// releases the lock.
+func LockFile(f string) (func() error, error) {
+	if err := EnsureDirExists(filepath.Dir(f)); err != nil {
+		return nil, err
+	}
+	fp, err := os.OpenFile(lockFilePath(f), os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockExclusive(fp); err != nil {
+		fp.Close()
+		return nil, err
+	}
+	return func() error {
+		unlockErr := unlock(fp)
+		if err := fp.Close(); err != nil {
+			return err
+		}
+		return unlockErr
+	}, nil
+}
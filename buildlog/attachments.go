@@ -0,0 +1,131 @@
+package buildlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Photos taken shortly after a work period ends are attributed to it
+const attachmentSlack = 30 * time.Minute
+
+var imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg"}
+
+func AttachmentPath(root string, a *protos.Attachment) string {
+	return filepath.Join(LogsDir(root), filepath.FromSlash(a.File))
+}
+
+func IsImageAttachment(a *protos.Attachment) bool {
+	return containsString(imageExtensions, strings.ToLower(path.Ext(a.File)))
+}
+
+func FileSHA256(f string) (string, error) {
+	fp, err := os.Open(f)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fp); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Returns the index of the attachment of the log entry with the checksum
+func FindAttachment(entry *protos.BuildLogEntry, sum string) (exists bool, index int) {
+	for i, a := range entry.Attachment {
+		if a.Sha256 == sum {
+			return true, i
+		}
+	}
+	return false, -1
+}
+
+// Returns the indices of log entries with a work period in progress at t
+func FindLogEntriesAt(logs []*protos.BuildLogEntry, t time.Time, now time.Time) []int {
+	var indices []int
+	for i, entry := range logs {
+		for _, wp := range entry.WorkPeriod {
+			start, err := WorkPeriodStart(entry, wp)
+			if err != nil {
+				continue
+			}
+			end := now
+			if !IsOpenWorkPeriod(wp) {
+				if end, err = WorkPeriodEnd(entry, wp); err != nil {
+					continue
+				}
+			}
+			if !t.Before(start) && !t.After(end.Add(attachmentSlack)) {
+				indices = append(indices, i)
+				break
+			}
+		}
+	}
+	return indices
+}
+
+// Copies src alongside the details file of the log entry, returning its path relative to the logs dir and whether it
+// was copied rather than already present. A numeric suffix is added to the file name if a different file of the same
+// name already exists.
+func CopyAttachment(root string, entry *protos.BuildLogEntry, src string, sum string) (string, bool, error) {
+	date, err := ParseDateOfLog(entry)
+	if err != nil {
+		return "", false, err
+	}
+	dir := LogDetailsDir(nil, date)
+	ext := filepath.Ext(src)
+	base := strings.TrimSuffix(filepath.Base(src), ext)
+	for n := 1; ; n++ {
+		name := base + ext
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		rel := path.Join(dir, name)
+		dst := filepath.Join(LogsDir(root), filepath.FromSlash(rel))
+		if exists, err := FileExists(dst); err != nil {
+			return "", false, err
+		} else if exists {
+			if existing, err := FileSHA256(dst); err != nil {
+				return "", false, err
+			} else if existing == sum {
+				return rel, false, nil
+			}
+			continue
+		}
+		if err := EnsureDirExists(filepath.Dir(dst)); err != nil {
+			return "", false, err
+		}
+		if err := copyFile(src, dst); err != nil {
+			return "", false, err
+		}
+		return rel, true, nil
+	}
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
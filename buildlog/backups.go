@@ -0,0 +1,98 @@
+package buildlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupsDirName   = ".backups"
+	backupTimeLayout = "20060102T150405.000000"
+	MaxBackups       = 20
+)
+
+type Backup struct {
+	Path    string
+	Created time.Time
+}
+
+func backupsDir(f string) string {
+	return filepath.Join(filepath.Dir(f), backupsDirName)
+}
+
+// Lists backups of f, most recent first
+func ListBackups(f string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupsDir(f))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(f) + "."
+	var backups []Backup
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		t, err := time.Parse(backupTimeLayout, strings.TrimPrefix(e.Name(), prefix))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			Path:    filepath.Join(backupsDir(f), e.Name()),
+			Created: t.Local(),
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.After(backups[j].Created)
+	})
+	return backups, nil
+}
+
+// Copies the current contents of f into the backups dir, removing the oldest backups beyond MaxBackups
+func BackupFile(f string) error {
+	data, err := os.ReadFile(f)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := EnsureDirExists(backupsDir(f)); err != nil {
+		return err
+	}
+	name := filepath.Base(f) + "." + time.Now().UTC().Format(backupTimeLayout)
+	if err := WriteFileAtomic(filepath.Join(backupsDir(f), name), data, 0644); err != nil {
+		return err
+	}
+	backups, err := ListBackups(f)
+	if err != nil {
+		return err
+	}
+	for i := MaxBackups; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replaces f with the contents of a backup, after first backing up the current contents of f
+func RestoreBackup(f string, backup Backup) error {
+	unlock, err := LockFile(f)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("Could not read backup %s\n%s", backup.Path, err.Error())
+	}
+	if err := BackupFile(f); err != nil {
+		return err
+	}
+	return WriteFileAtomic(f, data, 0644)
+}
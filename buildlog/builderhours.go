@@ -0,0 +1,142 @@
+package buildlog
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+var builderHoursTemplates = map[string]string{
+	"md":   "templates/builderhours.md.tmpl",
+	"html": "templates/builderhours.html.tmpl",
+}
+
+type BuilderHoursEntry struct {
+	*protos.BuildLogEntry
+	Details string
+	Minutes int
+	// Running totals of work time within the assembly and across the entire build, including this entry
+	AssemblyCumulativeMinutes int
+	CumulativeMinutes         int
+}
+
+type BuilderHoursChapter struct {
+	Assembly  string
+	FirstDate string
+	LastDate  string
+	Minutes   int
+	Entries   []*BuilderHoursEntry
+	Builders  []BuilderTotal
+}
+
+// Data model of the builder hours summary presented to an airworthiness inspector, with one chapter per assembly
+type BuilderHoursSummary struct {
+	Aircraft     string
+	Builder      string
+	Generated    time.Time
+	FirstDate    string
+	LastDate     string
+	TotalMinutes int
+	Chapters     []*BuilderHoursChapter
+	// Work time by builder, set only if any work is attributed to a builder
+	Builders []BuilderTotal
+	// Work time attributed to Builder, e.g., the applicant for a repairman certificate
+	BuilderMinutes int
+}
+
+func NewBuilderHoursSummary(root string, config *protos.ProjectConfig, logs []*protos.BuildLogEntry, aircraft string, builder string) (*BuilderHoursSummary, error) {
+	summary := &BuilderHoursSummary{
+		Aircraft:  aircraft,
+		Builder:   builder,
+		Generated: time.Now(),
+	}
+	chapters := map[string]*BuilderHoursChapter{}
+	chapterLogs := map[string][]*protos.BuildLogEntry{}
+	for _, log := range logs {
+		details, err := ReadLogDetails(root, log)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		minutes := LogEntryMinutes(log)
+		chapter, exists := chapters[log.Assembly]
+		if !exists {
+			chapter = &BuilderHoursChapter{
+				Assembly:  log.Assembly,
+				FirstDate: log.Date,
+			}
+			chapters[log.Assembly] = chapter
+			summary.Chapters = append(summary.Chapters, chapter)
+		}
+		chapterLogs[log.Assembly] = append(chapterLogs[log.Assembly], log)
+		chapter.LastDate = log.Date
+		chapter.Minutes += minutes
+		summary.TotalMinutes += minutes
+		chapter.Entries = append(chapter.Entries, &BuilderHoursEntry{
+			BuildLogEntry:             log,
+			Details:                   details,
+			Minutes:                   minutes,
+			AssemblyCumulativeMinutes: chapter.Minutes,
+			CumulativeMinutes:         summary.TotalMinutes,
+		})
+		if len(summary.FirstDate) == 0 {
+			summary.FirstDate = log.Date
+		}
+		summary.LastDate = log.Date
+	}
+	if HasAttributedWork(logs) {
+		summary.Builders = BuilderTotals(config, logs)
+		for _, t := range summary.Builders {
+			if t.Builder == builder {
+				summary.BuilderMinutes = t.Minutes
+			}
+		}
+		for _, chapter := range summary.Chapters {
+			chapter.Builders = BuilderTotals(config, chapterLogs[chapter.Assembly])
+		}
+	}
+	return summary, nil
+}
+
+func ValidBuilderHoursFormats() []string {
+	return []string{"md", "html"}
+}
+
+func LoadBuilderHoursTemplate(format string) (ExecutableTemplate, error) {
+	f, exists := builderHoursTemplates[format]
+	if !exists {
+		return nil, fmt.Errorf("No builder hours template for format %s", format)
+	}
+	text, err := builtinTemplates.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return parseBuilderHoursTemplate(format, f, string(text))
+}
+
+// Loads a template in place of the built-in template for the format
+func LoadBuilderHoursTemplateFile(format string, f string) (ExecutableTemplate, error) {
+	text, err := ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return parseBuilderHoursTemplate(format, f, text)
+}
+
+// Templates for the html format escape their output as HTML
+func parseBuilderHoursTemplate(format string, name string, text string) (ExecutableTemplate, error) {
+	if format == "html" {
+		return NewHTMLTemplate(name).Parse(text)
+	}
+	return NewTemplate(name).Parse(text)
+}
+
+func WriteBuilderHoursSummary(w io.Writer, tmpl ExecutableTemplate, summary *BuilderHoursSummary) error {
+	return tmpl.Execute(w, summary)
+}
@@ -0,0 +1,187 @@
+package buildlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+const (
+	BuilderEnvVar = "CCUB_BUILDER"
+
+	// Builder key of work periods not attributed to anyone
+	UnattributedKey = "(unattributed)"
+)
+
+type BuilderTotal struct {
+	Builder string `json:"builder"`
+	Minutes int    `json:"minutes"`
+	// Share of the total work time, from 0 to 100
+	Percent float64 `json:"percent"`
+}
+
+func builderItems(config *protos.ProjectConfig) []namedItem {
+	var items []namedItem
+	for _, b := range config.Builder {
+		items = append(items, namedItem{b.Name, b.Alias})
+	}
+	return items
+}
+
+func FindBuilder(config *protos.ProjectConfig, name string) *protos.Builder {
+	for _, b := range config.Builder {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Resolves a builder name or alias against the roster in the project config. Builders are free-form if the project
+// does not configure any.
+func ParseBuilderArg(config *protos.ProjectConfig, arg string) (string, error) {
+	items := builderItems(config)
+	if len(items) == 0 {
+		if name := strings.TrimSpace(arg); len(name) > 0 {
+			return name, nil
+		}
+		return "", errors.New("Builder must not be empty")
+	}
+	if name, ok := resolveName(items, arg); ok {
+		return name, nil
+	}
+	return "", fmt.Errorf("Builder must be one of:\n  %s", describeItems(items))
+}
+
+// Parses a comma-separated list of helpers. Helpers on the roster are resolved to their names; anyone else, e.g., a
+// student, is recorded as given.
+func ParseHelpersArg(config *protos.ProjectConfig, arg string) ([]string, error) {
+	items := builderItems(config)
+	var helpers []string
+	for _, v := range strings.Split(arg, ",") {
+		v = strings.TrimSpace(v)
+		if len(v) == 0 {
+			return nil, errors.New("Helpers must not be empty strings")
+		}
+		if name, ok := resolveName(items, v); ok {
+			v = name
+		}
+		if !containsString(helpers, v) {
+			helpers = append(helpers, v)
+		}
+	}
+	return helpers, nil
+}
+
+// Returns the builder to whom work is attributed unless otherwise specified: CCUB_BUILDER if set, otherwise the
+// default_builder of the project config, otherwise the only builder on the roster. Empty if none of these apply.
+func DefaultBuilder(config *protos.ProjectConfig) (string, error) {
+	if env := os.Getenv(BuilderEnvVar); len(env) > 0 {
+		b, err := ParseBuilderArg(config, env)
+		if err != nil {
+			return "", fmt.Errorf("Invalid %s\n%s", BuilderEnvVar, err.Error())
+		}
+		return b, nil
+	}
+	if len(config.DefaultBuilder) > 0 {
+		b, err := ParseBuilderArg(config, config.DefaultBuilder)
+		if err != nil {
+			return "", fmt.Errorf("Invalid default_builder in %s\n%s", ConfigFile, err.Error())
+		}
+		return b, nil
+	}
+	if len(config.Builder) == 1 {
+		return config.Builder[0].Name, nil
+	}
+	return "", nil
+}
+
+// Attributes a work period to a builder along with any helpers, leaving either unchanged if not specified
+func AttributeWorkPeriod(wp *protos.TimePeriod, builder string, helpers []string) {
+	if len(builder) > 0 {
+		wp.Builder = builder
+	}
+	if len(helpers) > 0 {
+		wp.Helper = helpers
+	}
+}
+
+func workPeriodBuilderKey(wp *protos.TimePeriod) string {
+	if len(wp.Builder) == 0 {
+		return UnattributedKey
+	}
+	return wp.Builder
+}
+
+// Sums the work time of a log entry by builder, keyed by UnattributedKey for work periods not attributed to anyone
+func LogEntryBuilderMinutes(entry *protos.BuildLogEntry) map[string]int {
+	minutes := map[string]int{}
+	for _, wp := range entry.WorkPeriod {
+		minutes[workPeriodBuilderKey(wp)] += int(wp.DurationMin)
+	}
+	return minutes
+}
+
+// Whether any work period of the log entries is attributed to a builder
+func HasAttributedWork(logs []*protos.BuildLogEntry) bool {
+	for _, entry := range logs {
+		for _, wp := range entry.WorkPeriod {
+			if len(wp.Builder) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Orders builders as on the roster, followed alphabetically by any not on it and finally by UnattributedKey
+func SortBuilders(config *protos.ProjectConfig, builders []string) {
+	rank := map[string]int{}
+	for i, b := range config.Builder {
+		rank[b.Name] = i
+	}
+	sort.SliceStable(builders, func(i, j int) bool {
+		bi, bj := builders[i], builders[j]
+		if (bi == UnattributedKey) != (bj == UnattributedKey) {
+			return bj == UnattributedKey
+		}
+		ri, iok := rank[bi]
+		rj, jok := rank[bj]
+		if iok != jok {
+			return iok
+		} else if iok {
+			return ri < rj
+		}
+		return bi < bj
+	})
+}
+
+// Totals the work time of the log entries by builder, ordered as by SortBuilders
+func BuilderTotals(config *protos.ProjectConfig, logs []*protos.BuildLogEntry) []BuilderTotal {
+	minutes := map[string]int{}
+	total := 0
+	for _, entry := range logs {
+		for b, m := range LogEntryBuilderMinutes(entry) {
+			minutes[b] += m
+			total += m
+		}
+	}
+	var builders []string
+	for b := range minutes {
+		builders = append(builders, b)
+	}
+	SortBuilders(config, builders)
+	var totals []BuilderTotal
+	for _, b := range builders {
+		t := BuilderTotal{Builder: b, Minutes: minutes[b]}
+		if total > 0 {
+			t.Percent = float64(minutes[b]) * 100 / float64(total)
+		}
+		totals = append(totals, t)
+	}
+	return totals
+}
@@ -3,6 +3,7 @@ package buildlog
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -12,20 +13,17 @@ import (
 )
 
 const (
-	LogsDir            = "log"
-	LogsFile           = "buildlog.textproto"
-	LogsPath           = LogsDir + "/" + LogsFile
+	LogsDirName        = "log"
 	logDetailsTemplate = ""
 )
 
-var validAssemblies = []string{
-	"left wing",
-	"right wing",
-	"fuselage",
-	"skin",
-	"avionics",
-	"powerplant",
-	"gear",
+func LogsDir(root string) string {
+	return filepath.Join(root, LogsDirName)
+}
+
+// Path of the logs metadata file, named for the storage configured by the project
+func LogsPath(root string) string {
+	return filepath.Join(LogsDir(root), ProjectLogStorage(root).FileName())
 }
 
 func containsString(s []string, str string) bool {
@@ -47,27 +45,35 @@ func LogExists(date time.Time, logs []*protos.BuildLogEntry) (exists bool, index
 	return false, -1
 }
 
+// Reads the logs metadata file f, or a backup of one, in the storage given by its name
 func ReadLogs(f string) (*protos.BuildLogs, error) {
-	text, err := ReadFile(f)
+	data, err := os.ReadFile(f)
+	if os.IsNotExist(err) {
+		if err := checkLogStorage(f); err != nil {
+			return &protos.BuildLogs{}, err
+		}
+	}
 	if err != nil {
 		return &protos.BuildLogs{}, err
 	}
 	entries := protos.BuildLogs{}
-	err = proto.UnmarshalText(text, &entries)
+	err = LogStorageOfFile(f).Unmarshal(data, &entries)
 	return &entries, err
 }
 
 func WriteLogs(f string, logs *protos.BuildLogs) error {
-	err := EnsureDirExists(LogsDir)
+	err := EnsureDirExists(filepath.Dir(f))
 	if err != nil {
 		return err
 	}
-	fp, err := os.Create(f)
+	if err := BackupFile(f); err != nil {
+		return fmt.Errorf("Could not back up %s\n%s", f, err.Error())
+	}
+	data, err := LogStorageOfFile(f).Marshal(logs)
 	if err != nil {
 		return err
 	}
-	defer fp.Close()
-	return proto.MarshalText(fp, logs)
+	return WriteFileAtomic(f, data, 0644)
 }
 
 func PrettyPrintLogEntry(entry *protos.BuildLogEntry) string {
@@ -82,15 +88,32 @@ func LogDetailsFileUnderBasedir(basedir []string, date time.Time) string {
 	return strings.Join([]string{LogDetailsDir(basedir, date), date.Format(DateLayout) + ".md"}, "/")
 }
 
-func LogDetailsFile(date time.Time) string {
-	return LogDetailsFileUnderBasedir([]string{LogsDir}, date)
+// Path of the details file of the log entry with the specified ID relative to the logs dir, as recorded in
+// BuildLogEntry.DetailsFile
+func RelativeLogDetailsFile(date time.Time, id string) string {
+	return strings.Join([]string{LogDetailsDir(nil, date), id + ".md"}, "/")
+}
+
+// Path of a details file relative to the logs dir. Older log entries recorded the details file relative to the
+// project root rather than the logs dir.
+func LogDetailsRelativePath(detailsFile string) string {
+	return strings.TrimPrefix(detailsFile, LogsDirName+"/")
+}
+
+func LogDetailsPath(root string, entry *protos.BuildLogEntry) string {
+	return filepath.Join(LogsDir(root), LogDetailsRelativePath(entry.DetailsFile))
+}
+
+func ReadLogDetails(root string, entry *protos.BuildLogEntry) (string, error) {
+	details, err := ReadFile(LogDetailsPath(root, entry))
+	return strings.TrimSpace(details), err
 }
 
-func CreateLogDetailsFile(assembly string, date time.Time, overwrite bool) (string, error) {
-	if err := EnsureDirExists(LogDetailsDir([]string{LogsDir}, date)); err != nil {
+func CreateLogDetailsFile(root string, entry *protos.BuildLogEntry, overwrite bool) (string, error) {
+	f := LogDetailsPath(root, entry)
+	if err := EnsureDirExists(filepath.Dir(f)); err != nil {
 		return "", err
 	}
-	f := LogDetailsFile(date)
 	if !overwrite {
 		if exists, err := FileExists(f); err != nil {
 			return "", err
@@ -112,11 +135,10 @@ func InsertLogUpdater(entry *protos.BuildLogEntry) LogUpdater {
 		if err != nil {
 			return logs, err
 		}
-		exists, _ := LogExists(date, logs)
-		if exists {
-			return logs, fmt.Errorf("Log entry already exists for %s", entry.Date)
+		if exists, index := FindLogEntry(logs, date, entry.Assembly); exists {
+			return logs, fmt.Errorf("Log entry %s already exists for %s on %s", LogEntryID(logs[index]), entry.Assembly, entry.Date)
 		}
-		return append(logs, entry), nil
+		return AppendLogEntry(logs, entry), nil
 	}
 }
 
@@ -126,40 +148,56 @@ func UpsertLogUpdater(entry *protos.BuildLogEntry) LogUpdater {
 		if err != nil {
 			return logs, err
 		}
-		exists, index := LogExists(date, logs)
-		if exists {
+		if exists, index := FindLogEntry(logs, date, entry.Assembly); exists {
+			// Replacement retains the identity and details of the original log entry
+			entry.Id = LogEntryID(logs[index])
+			entry.DetailsFile = logs[index].DetailsFile
 			logs[index] = entry
 		} else {
-			logs = append(logs, entry)
+			logs = AppendLogEntry(logs, entry)
 		}
 		return logs, nil
 	}
 }
 
+// Applies update to the logs in f while holding an exclusive lock, such that concurrent updates are serialized.
+// The resulting changes are recorded in the journal.
 func UpdateLogMetadataFile(f string, update LogUpdater) error {
+	return updateLogMetadataFile(f, update, &protos.JournalEntry{})
+}
+
+func updateLogMetadataFile(f string, update LogUpdater, journal *protos.JournalEntry) error {
+	unlock, err := LockFile(f)
+	if err != nil {
+		return fmt.Errorf("Could not lock %s\n%s", f, err.Error())
+	}
+	defer unlock()
+
 	logs, err := ReadLogs(f)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("Could not open logs metadata from %s\n%s", f, err.Error())
 	}
 
+	// Log entries are matched by ID in the journal
+	AssignLogEntryIDs(logs.LogEntry)
+	before := cloneLogEntries(logs.LogEntry)
+
 	logs.LogEntry, err = update(logs.LogEntry)
 	if err != nil {
 		return err
 	}
 
-	// Ensure logs are ordered by date
-	sort.Slice(logs.LogEntry, func(i, j int) bool {
+	AssignLogEntryIDs(logs.LogEntry)
+
+	// Ensure logs are ordered by date, retaining the order of log entries on the same day
+	sort.SliceStable(logs.LogEntry, func(i, j int) bool {
 		it, _ := ParseDateOfLog(logs.LogEntry[i])
 		jt, _ := ParseDateOfLog(logs.LogEntry[j])
 		return jt.After(it)
 	})
 
-	return WriteLogs(f, logs)
-}
-
-func ParseAssemblyArg(arg string) (string, error) {
-	if !containsString(validAssemblies, arg) {
-		return "", fmt.Errorf("Assembly must be one of:\n  %s", strings.Join(validAssemblies, "\n  "))
+	if err := WriteLogs(f, logs); err != nil {
+		return err
 	}
-	return arg, nil
+	return journalLogUpdate(f, before, logs.LogEntry, journal)
 }
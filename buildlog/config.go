@@ -0,0 +1,61 @@
+package buildlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+const ConfigFile = "ccub.textproto"
+
+func ConfigPath(root string) string {
+	return filepath.Join(root, ConfigFile)
+}
+
+// Assemblies of a Carbon Cub EX2, used when a project does not configure its own
+var defaultAssemblies = []string{
+	"left wing",
+	"right wing",
+	"fuselage",
+	"skin",
+	"avionics",
+	"powerplant",
+	"gear",
+}
+
+func DefaultProjectConfig() *protos.ProjectConfig {
+	config := &protos.ProjectConfig{}
+	for _, a := range defaultAssemblies {
+		config.Assembly = append(config.Assembly, &protos.Assembly{Name: a})
+	}
+	return config
+}
+
+// Reads the project config, falling back to the default config if the file does not exist
+func ReadProjectConfig(root string) (*protos.ProjectConfig, error) {
+	f := ConfigPath(root)
+	text, err := ReadFile(f)
+	if os.IsNotExist(err) {
+		return DefaultProjectConfig(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	config := &protos.ProjectConfig{}
+	if err := proto.UnmarshalText(text, config); err != nil {
+		return nil, fmt.Errorf("Could not parse project config %s\n%s", f, err.Error())
+	}
+	if _, err := FindLogStorage(config.Storage); err != nil {
+		return nil, fmt.Errorf("Invalid storage in project config %s\n%s", f, err.Error())
+	}
+	if len(config.Assembly) == 0 {
+		config.Assembly = DefaultProjectConfig().Assembly
+	}
+	return config, nil
+}
+
+func WriteProjectConfig(root string, config *protos.ProjectConfig) error {
+	return os.WriteFile(ConfigPath(root), []byte(proto.MarshalTextString(config)), 0666)
+}
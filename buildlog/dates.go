@@ -2,7 +2,6 @@ package buildlog
 
 import (
 	"fmt"
-	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -33,7 +32,7 @@ func timeAddYear(t time.Time) time.Time {
 
 var validDateLayouts = []dateForm{
 	{DateLayout, timeNoAdjust},
-	{"2006-_1-_2", timeNoAdjust},
+	{"2006-1-_2", timeNoAdjust},
 	{"Jan-_2", timeAddYear},
 	{"1-_2", timeAddYear},
 	{"1/_2", timeAddYear},
@@ -48,18 +47,18 @@ func ValidDateFormats() []string {
 }
 
 func SameDayKitchenTimeDiff(end string, start string) (time.Duration, error) {
-	tend, err := ParseKitchenTime(0, 0, 0, end)
+	tend, err := ParseKitchenTime(0, 0, 0, end, time.UTC)
 	if err != nil {
 		return 0, err
 	}
-	tstart, err := ParseKitchenTime(0, 0, 0, start)
+	tstart, err := ParseKitchenTime(0, 0, 0, start, time.UTC)
 	if err != nil {
 		return 0, err
 	}
 	return tend.Sub(tstart), nil
 }
 
-func ParseKitchenTime(year int, month time.Month, day int, kitchen string) (time.Time, error) {
+func ParseKitchenTime(year int, month time.Month, day int, kitchen string, loc *time.Location) (time.Time, error) {
 	submatches := kitchenTimePattern.FindStringSubmatch(kitchen)
 	if submatches == nil {
 		return time.Time{}, fmt.Errorf("Invalid time: %s", kitchen)
@@ -79,15 +78,15 @@ func ParseKitchenTime(year int, month time.Month, day int, kitchen string) (time
 	if hours > 23 || minutes > 59 {
 		return time.Time{}, fmt.Errorf("Invalid time: %s", kitchen)
 	}
-	return time.Date(year, month, day, hours, minutes, 0, 0, time.UTC), nil
+	return time.Date(year, month, day, hours, minutes, 0, 0, loc), nil
 }
 
 func ParseDateArg(arg string) (time.Time, error) {
 	if len(arg) > 0 {
 		// Accept any partial spelling of "today" or "yesterday", e.g. "t" or "y"
-		if arg == "today"[0:len(arg)] {
+		if strings.HasPrefix("today", arg) {
 			return time.Now(), nil
-		} else if arg == "yesterday"[0:len(arg)] {
+		} else if strings.HasPrefix("yesterday", arg) {
 			return time.Now().AddDate(0, 0, -1), nil
 		}
 	}
@@ -107,22 +106,18 @@ func ParseWorkPeriodsArg(year int, month time.Month, day int, arg string) ([]*pr
 		if len(s) != 2 {
 			return nil, fmt.Errorf("Time period must consist of both a start time and an end time")
 		}
-		start, err := ParseKitchenTime(year, month, day, s[0])
+		start, err := ParseKitchenTime(year, month, day, s[0], time.Local)
 		if err != nil {
 			return nil, fmt.Errorf("Bad start time: %s", s[0])
 		}
-		end, err := ParseKitchenTime(year, month, day, s[1])
+		end, err := ParseKitchenTime(year, month, day, s[1], time.Local)
 		if err != nil {
 			return nil, fmt.Errorf("Bad end time: %s", s[1])
 		}
 		if start.After(end) {
 			return nil, fmt.Errorf("Start time %s is after end time %s", s[0], s[1])
 		}
-		periods = append(periods, &protos.TimePeriod{
-			StartTime:   start.Format(time.Kitchen),
-			EndTime:     end.Format(time.Kitchen),
-			DurationMin: uint32(math.Ceil(end.Sub(start).Minutes())),
-		})
+		periods = append(periods, NewWorkPeriod(start, end))
 	}
 	return periods, nil
 }
@@ -134,3 +129,19 @@ func ParseDateOfLog(log *protos.BuildLogEntry) (time.Time, error) {
 func FormatDateForLog(date time.Time) string {
 	return date.Format(DateLayout)
 }
+
+func TruncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Whether date falls on or between the days of from and to, either of which may be the zero time to leave the range unbounded
+func IsDateInRange(date time.Time, from time.Time, to time.Time) bool {
+	d := TruncateToDate(date)
+	if !from.IsZero() && d.Before(TruncateToDate(from)) {
+		return false
+	}
+	if !to.IsZero() && d.After(TruncateToDate(to)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package buildlog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Returns the ID of a log entry; log entries created before IDs existed are identified by their date
+func LogEntryID(entry *protos.BuildLogEntry) string {
+	if len(entry.Id) > 0 {
+		return entry.Id
+	}
+	return entry.Date
+}
+
+func FindLogEntryByID(logs []*protos.BuildLogEntry, id string) (exists bool, index int) {
+	for i, v := range logs {
+		if LogEntryID(v) == id {
+			return true, i
+		}
+	}
+	return false, -1
+}
+
+// Returns the indices of all log entries on date, restricted to those for assembly unless it is empty
+func FindLogEntries(logs []*protos.BuildLogEntry, date time.Time, assembly string) []int {
+	d := FormatDateForLog(date)
+	var indices []int
+	for i, v := range logs {
+		if v.Date == d && (len(assembly) == 0 || v.Assembly == assembly) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func FindLogEntry(logs []*protos.BuildLogEntry, date time.Time, assembly string) (exists bool, index int) {
+	if indices := FindLogEntries(logs, date, assembly); len(indices) > 0 {
+		return true, indices[0]
+	}
+	return false, -1
+}
+
+// Returns an unused ID for a new log entry on date: the date itself for the first log entry of the day, followed by
+// the date suffixed with -2, -3, ...
+func NextLogEntryID(logs []*protos.BuildLogEntry, date time.Time) string {
+	d := FormatDateForLog(date)
+	id := d
+	for n := 2; ; n++ {
+		if exists, _ := FindLogEntryByID(logs, id); !exists {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", d, n)
+	}
+}
+
+// Appends a new log entry, assigning it an ID and details file if it does not already have them
+func AppendLogEntry(logs []*protos.BuildLogEntry, entry *protos.BuildLogEntry) []*protos.BuildLogEntry {
+	if date, err := ParseDateOfLog(entry); err == nil {
+		if len(entry.Id) == 0 {
+			entry.Id = NextLogEntryID(logs, date)
+		}
+		if len(entry.DetailsFile) == 0 {
+			entry.DetailsFile = RelativeLogDetailsFile(date, entry.Id)
+		}
+	}
+	return append(logs, entry)
+}
+
+// Records the ID of every log entry that does not yet have one
+func AssignLogEntryIDs(logs []*protos.BuildLogEntry) {
+	used := map[string]bool{}
+	for _, entry := range logs {
+		used[entry.Id] = true
+	}
+	for _, entry := range logs {
+		if len(entry.Id) > 0 {
+			continue
+		}
+		id := entry.Date
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", entry.Date, n)
+		}
+		entry.Id = id
+		used[id] = true
+	}
+}
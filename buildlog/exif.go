@@ -0,0 +1,166 @@
+package buildlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	exifDateTimeLayout = "2006:01:02 15:04:05"
+
+	tiffTagDateTime           = 0x0132
+	tiffTagExifIFD            = 0x8769
+	exifTagDateTimeOriginal   = 0x9003
+	exifTagDateTimeDigitized  = 0x9004
+	exifTagOffsetTimeOriginal = 0x9011
+	tiffTypeASCII             = 2
+	tiffTypeLong              = 4
+)
+
+var errNoExif = errors.New("No EXIF metadata")
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func newTIFFReader(data []byte) (*tiffReader, error) {
+	if len(data) < 8 {
+		return nil, errNoExif
+	}
+	r := &tiffReader{data: data}
+	switch string(data[:4]) {
+	case "II*\x00":
+		r.order = binary.LittleEndian
+	case "MM\x00*":
+		r.order = binary.BigEndian
+	default:
+		return nil, errNoExif
+	}
+	return r, nil
+}
+
+// Returns the raw value of each tag in the IFD at offset, keyed by tag
+func (r *tiffReader) readIFD(offset uint32) map[uint16]string {
+	tags := map[uint16]string{}
+	if int(offset)+2 > len(r.data) {
+		return tags
+	}
+	n := int(r.order.Uint16(r.data[offset:]))
+	for i := 0; i < n; i++ {
+		e := int(offset) + 2 + 12*i
+		if e+12 > len(r.data) {
+			break
+		}
+		tag := r.order.Uint16(r.data[e:])
+		typ := r.order.Uint16(r.data[e+2:])
+		count := r.order.Uint32(r.data[e+4:])
+		switch {
+		case typ == tiffTypeLong && count == 1:
+			tags[tag] = string(r.data[e+8 : e+12])
+		case typ == tiffTypeASCII:
+			value := r.data[e+8 : e+12]
+			if count > 4 {
+				start := r.order.Uint32(r.data[e+8:])
+				if int(start)+int(count) > len(r.data) {
+					continue
+				}
+				value = r.data[start : start+count]
+			}
+			tags[tag] = strings.TrimRight(string(value[:min32(count, uint32(len(value)))]), "\x00 ")
+		}
+	}
+	return tags
+}
+
+func min32(a uint32, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Returns the time at which a TIFF image was taken; timestamps that do not record a UTC offset are interpreted in
+// loc
+func (r *tiffReader) captureTime(loc *time.Location) (time.Time, error) {
+	ifd0 := r.readIFD(r.order.Uint32(r.data[4:]))
+	var exif map[uint16]string
+	if p, exists := ifd0[tiffTagExifIFD]; exists {
+		exif = r.readIFD(r.order.Uint32([]byte(p)))
+	}
+	for _, ts := range []string{exif[exifTagDateTimeOriginal], exif[exifTagDateTimeDigitized], ifd0[tiffTagDateTime]} {
+		if len(ts) == 0 {
+			continue
+		}
+		if offset := exif[exifTagOffsetTimeOriginal]; len(offset) > 0 {
+			if t, err := time.Parse(exifDateTimeLayout+"-07:00", ts+offset); err == nil {
+				return t, nil
+			}
+		}
+		if t, err := time.ParseInLocation(exifDateTimeLayout, ts, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errNoExif
+}
+
+// Returns the EXIF segment of a JPEG image
+func jpegExif(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errNoExif
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil, errNoExif
+		}
+		marker := data[i+1]
+		// Image data follows the start of scan marker
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if end > len(data) {
+			break
+		}
+		if marker == 0xE1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return data[i+10 : end], nil
+		}
+		i = end
+	}
+	return nil, errNoExif
+}
+
+// Reads the time at which a photo was taken from its EXIF metadata. JPEG and TIFF-based images, including most raw
+// formats, are supported. Returns false if the file does not record a capture time.
+func ReadCaptureTime(f string, loc *time.Location) (time.Time, bool, error) {
+	fp, err := os.Open(f)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer fp.Close()
+	// Metadata precedes the image data: within the first 64KiB for JPEG, whose APP1 segment can be no larger, and
+	// within the first 1MiB for the TIFF-based raw formats in practice
+	data, err := io.ReadAll(io.LimitReader(fp, 1<<20))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	tiff := data
+	if exif, err := jpegExif(data); err == nil {
+		tiff = exif
+	}
+	r, err := newTIFFReader(tiff)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	t, err := r.captureTime(loc)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	return t, true, nil
+}
@@ -0,0 +1,258 @@
+package buildlog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Writes exported log entries or work periods in a format read by other tools
+type Exporter interface {
+	Export(w io.Writer, export *Export) error
+}
+
+var exporters = map[string]Exporter{
+	"csv":   csvExporter{},
+	"json":  jsonExporter{},
+	"jsonl": jsonlExporter{},
+	"ics":   icsExporter{},
+	"xlsx":  xlsxExporter{},
+}
+
+// Columns of tabular exports, named as the fields read by 'import'. The period column is only included when exporting
+// work periods, and the details column along with details.
+var ExportColumns = []string{"id", "period", "date", "assembly", "subassembly", "title", "tags", "start", "end", "minutes", "builder", "helpers", "details"}
+
+type Export struct {
+	Rows []*ExportRow
+	// Whether each row is a work period rather than a log entry
+	Periods bool
+	Details bool
+}
+
+// A log entry, or one of its work periods
+type ExportRow struct {
+	ID          string   `json:"id"`
+	Date        string   `json:"date"`
+	Assembly    string   `json:"assembly"`
+	Subassembly []string `json:"subassembly,omitempty"`
+	Title       string   `json:"title"`
+	Tags        []string `json:"tags,omitempty"`
+	// Of the work period, or of the earliest and latest work periods of a log entry; end is empty while work is
+	// ongoing
+	Start   string `json:"start,omitempty"`
+	End     string `json:"end,omitempty"`
+	Minutes int    `json:"minutes"`
+	// Builders and helpers of the work period, or of any work period of a log entry
+	Builder []string `json:"builder,omitempty"`
+	Helpers []string `json:"helpers,omitempty"`
+	Details string   `json:"details,omitempty"`
+	// Position of the work period within its log entry, from 1
+	Period int `json:"period,omitempty"`
+	start  time.Time
+	end    time.Time
+}
+
+func ExportFormats() []string {
+	var formats []string
+	for f := range exporters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func FindExporter(format string) (Exporter, error) {
+	if e, ok := exporters[format]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("Export format must be one of:\n  %s", strings.Join(ExportFormats(), "\n  "))
+}
+
+// Returns the log entries, or their work periods if periods is set, as rows to export, optionally along with the text
+// of the details file of each log entry
+func NewExport(root string, logs []*protos.BuildLogEntry, periods bool, details bool) (*Export, error) {
+	export := &Export{Periods: periods, Details: details}
+	for _, entry := range logs {
+		base := ExportRow{
+			ID:          LogEntryID(entry),
+			Date:        entry.Date,
+			Assembly:    entry.Assembly,
+			Subassembly: entry.Subassembly,
+			Title:       entry.Title,
+			Tags:        entry.Tags,
+		}
+		if details {
+			text, err := ReadLogDetails(root, entry)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			base.Details = strings.TrimSpace(text)
+		}
+		if !periods {
+			row := base
+			row.Minutes = LogEntryMinutes(entry)
+			ongoing := false
+			for _, wp := range entry.WorkPeriod {
+				start, end, err := exportWorkPeriodTimes(entry, wp)
+				if err != nil {
+					return nil, err
+				}
+				if row.start.IsZero() || start.Before(row.start) {
+					row.start = start
+				}
+				if end.IsZero() {
+					ongoing = true
+				} else if end.After(row.end) {
+					row.end = end
+				}
+				row.Builder = appendMissing(row.Builder, wp.Builder)
+				row.Helpers = appendMissing(row.Helpers, wp.Helper...)
+			}
+			if ongoing {
+				row.end = time.Time{}
+			}
+			finishExportRow(&row)
+			export.Rows = append(export.Rows, &row)
+			continue
+		}
+		for i, wp := range entry.WorkPeriod {
+			row := base
+			row.Period = i + 1
+			row.Minutes = int(wp.DurationMin)
+			var err error
+			if row.start, row.end, err = exportWorkPeriodTimes(entry, wp); err != nil {
+				return nil, err
+			}
+			if len(wp.Builder) > 0 {
+				row.Builder = []string{wp.Builder}
+			}
+			row.Helpers = wp.Helper
+			finishExportRow(&row)
+			export.Rows = append(export.Rows, &row)
+		}
+	}
+	return export, nil
+}
+
+// Returns the start and end of a work period; the end is the zero time while it is ongoing
+func exportWorkPeriodTimes(entry *protos.BuildLogEntry, wp *protos.TimePeriod) (time.Time, time.Time, error) {
+	start, err := WorkPeriodStart(entry, wp)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if IsOpenWorkPeriod(wp) {
+		return start, time.Time{}, nil
+	}
+	end, err := WorkPeriodEnd(entry, wp)
+	return start, end, err
+}
+
+func finishExportRow(row *ExportRow) {
+	// Work periods not attributed to anyone are omitted from the builders of a log entry
+	var builders []string
+	for _, b := range row.Builder {
+		if len(b) > 0 {
+			builders = append(builders, b)
+		}
+	}
+	row.Builder = builders
+	if !row.start.IsZero() {
+		row.Start = row.start.Format(time.RFC3339)
+	}
+	if !row.end.IsZero() {
+		row.End = row.end.Format(time.RFC3339)
+	}
+}
+
+// Leaves out rows of work still ongoing or of no length, such as for calendar events, which need both a start and a
+// later end. Rows of log entries without work periods are kept. Returns the number of rows left out.
+func (e *Export) OmitUntimedRows() int {
+	var rows []*ExportRow
+	for _, row := range e.Rows {
+		if row.start.IsZero() || row.end.After(row.start) {
+			rows = append(rows, row)
+		}
+	}
+	omitted := len(e.Rows) - len(rows)
+	e.Rows = rows
+	return omitted
+}
+
+func (e *Export) Columns() []string {
+	var columns []string
+	for _, c := range ExportColumns {
+		if (c != "period" || e.Periods) && (c != "details" || e.Details) {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// Returns the value of each column of a row, joining lists with commas
+func (e *Export) Record(row *ExportRow) []string {
+	values := map[string]string{
+		"id":          row.ID,
+		"period":      strconv.Itoa(row.Period),
+		"date":        row.Date,
+		"assembly":    row.Assembly,
+		"subassembly": strings.Join(row.Subassembly, ","),
+		"title":       row.Title,
+		"tags":        strings.Join(row.Tags, ","),
+		"start":       row.Start,
+		"end":         row.End,
+		"minutes":     strconv.Itoa(row.Minutes),
+		"builder":     strings.Join(row.Builder, ","),
+		"helpers":     strings.Join(row.Helpers, ","),
+		"details":     row.Details,
+	}
+	var record []string
+	for _, c := range e.Columns() {
+		record = append(record, values[c])
+	}
+	return record
+}
+
+type csvExporter struct{}
+
+func (csvExporter) Export(w io.Writer, export *Export) error {
+	cw := csv.NewWriter(w)
+	cw.Write(export.Columns())
+	for _, row := range export.Rows {
+		cw.Write(export.Record(row))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type jsonExporter struct{}
+
+func (jsonExporter) Export(w io.Writer, export *Export) error {
+	rows := export.Rows
+	if rows == nil {
+		rows = []*ExportRow{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
+
+type jsonlExporter struct{}
+
+func (jsonlExporter) Export(w io.Writer, export *Export) error {
+	enc := json.NewEncoder(w)
+	for _, row := range export.Rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
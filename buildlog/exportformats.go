@@ -0,0 +1,178 @@
+package buildlog
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+var icsTextEscaper = strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\r\n", "\\n", "\n", "\\n")
+
+// Writes an iCalendar event for each row, with the assembly and tags as categories such that 'import' reads the
+// events back onto the same assemblies. Log entries without work periods are exported as all-day events, which
+// 'import' skips. Rows of work still ongoing or of no length are expected to be left out by OmitUntimedRows.
+type icsExporter struct{}
+
+func (icsExporter) Export(w io.Writer, export *Export) error {
+	var b strings.Builder
+	line := func(name string, value string) {
+		writeICSLine(&b, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//ccub//Build log//EN")
+	line("CALSCALE", "GREGORIAN")
+	for _, row := range export.Rows {
+		uid := row.ID
+		if row.Period > 0 {
+			uid = fmt.Sprintf("%s-%d", row.ID, row.Period)
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", uid+"@ccub")
+		if row.start.IsZero() {
+			date, err := time.Parse(DateLayout, row.Date)
+			if err != nil {
+				return err
+			}
+			line("DTSTAMP", date.Format(icsTimeLayout))
+			line("DTSTART;VALUE=DATE", date.Format("20060102"))
+		} else {
+			// The start rather than the time of export, such that exporting again yields the same calendar
+			line("DTSTAMP", row.start.UTC().Format(icsTimeLayout))
+			line("DTSTART", row.start.UTC().Format(icsTimeLayout))
+			line("DTEND", row.end.UTC().Format(icsTimeLayout))
+		}
+		summary := row.Title
+		if len(summary) == 0 {
+			summary = row.Assembly
+		}
+		line("SUMMARY", icsTextEscaper.Replace(summary))
+		var categories []string
+		for _, c := range append([]string{row.Assembly}, row.Tags...) {
+			categories = append(categories, icsTextEscaper.Replace(c))
+		}
+		line("CATEGORIES", strings.Join(categories, ","))
+		var description []string
+		if len(row.Builder) > 0 {
+			description = append(description, "Builder: "+strings.Join(row.Builder, ", "))
+		}
+		if len(row.Helpers) > 0 {
+			description = append(description, "Helpers: "+strings.Join(row.Helpers, ", "))
+		}
+		if len(row.Details) > 0 {
+			if len(description) > 0 {
+				description = append(description, "")
+			}
+			description = append(description, row.Details)
+		}
+		if len(description) > 0 {
+			line("DESCRIPTION", icsTextEscaper.Replace(strings.Join(description, "\n")))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Writes a content line, folding it onto continuation lines of at most 75 octets without splitting characters
+func writeICSLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+		line = line[i:]
+		// The leading space of a continuation line counts towards its length
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// Writes a single worksheet workbook, with the minutes and period columns as numbers
+type xlsxExporter struct{}
+
+type xlsxPart struct {
+	name    string
+	content string
+}
+
+// Parts of a workbook other than its worksheet
+var xlsxParts = []xlsxPart{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Build log" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func (xlsxExporter) Export(w io.Writer, export *Export) error {
+	zw := zip.NewWriter(w)
+	for _, part := range append(xlsxParts, xlsxPart{"xl/worksheets/sheet1.xml", xlsxSheet(export)}) {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, xml.Header+part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func xlsxSheet(export *Export) string {
+	var b bytes.Buffer
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	columns := export.Columns()
+	writeRow := func(r int, values []string, numeric bool) {
+		fmt.Fprintf(&b, `<row r="%d">`, r)
+		for i, v := range values {
+			ref := xlsxColumnName(i) + strconv.Itoa(r)
+			if numeric && (columns[i] == "minutes" || columns[i] == "period") {
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, v)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(&b, []byte(v))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	writeRow(1, columns, false)
+	for i, row := range export.Rows {
+		writeRow(i+2, export.Record(row), true)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// Returns the name of a column from its index, e.g., A for 0 and AA for 26
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
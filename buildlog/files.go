@@ -37,8 +37,7 @@ func LoadTemplateFromFile(f string) (*template.Template, error) {
 	if err != nil {
 		return nil, err
 	}
-	tmpl := template.New(f)
-	return tmpl.Parse(text)
+	return NewTemplate(f).Parse(text)
 }
 
 func EnsureDirExists(dir string) error {
@@ -0,0 +1,81 @@
+package buildlog
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Selects log entries matching every criterion that is set; the zero value matches all log entries
+type LogFilter struct {
+	// Inclusive; zero times are unbounded
+	From time.Time
+	To   time.Time
+	// Top-level assembly
+	Assembly string
+	// Log entries must have at least one of the subassemblies
+	Subassemblies []string
+	// Log entries must have at least one of the tags, or all of them if AllTags is set
+	Tags    []string
+	AllTags bool
+	// Total work time of the log entry
+	MinMinutes int
+	Title      *regexp.Regexp
+}
+
+func (f *LogFilter) Matches(entry *protos.BuildLogEntry) (bool, error) {
+	date, err := ParseDateOfLog(entry)
+	if err != nil {
+		return false, err
+	}
+	if !IsDateInRange(date, f.From, f.To) {
+		return false, nil
+	}
+	if len(f.Assembly) > 0 && entry.Assembly != f.Assembly {
+		return false, nil
+	}
+	if len(f.Subassemblies) > 0 && !containsAnyString(entry.Subassembly, f.Subassemblies) {
+		return false, nil
+	}
+	if len(f.Tags) > 0 {
+		if f.AllTags {
+			for _, t := range f.Tags {
+				if !containsString(entry.Tags, t) {
+					return false, nil
+				}
+			}
+		} else if !containsAnyString(entry.Tags, f.Tags) {
+			return false, nil
+		}
+	}
+	if f.MinMinutes > 0 && LogEntryMinutes(entry) < f.MinMinutes {
+		return false, nil
+	}
+	if f.Title != nil && !f.Title.MatchString(entry.Title) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// Returns the log entries matching the filter, retaining their order
+func FilterLogs(logs []*protos.BuildLogEntry, f *LogFilter) ([]*protos.BuildLogEntry, error) {
+	var matches []*protos.BuildLogEntry
+	for _, entry := range logs {
+		if match, err := f.Matches(entry); err != nil {
+			return nil, err
+		} else if match {
+			matches = append(matches, entry)
+		}
+	}
+	return matches, nil
+}
+
+func containsAnyString(s []string, strs []string) bool {
+	for _, str := range strs {
+		if containsString(s, str) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,354 @@
+package buildlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+const logsGitignoreFile = ".gitignore"
+
+// Files kept alongside the logs that are not to be committed: backups, locks, caches and the temporary files of
+// atomic writes
+var logsGitignoreRules = []string{".backups/", ".*.lock", ".*.tmp*", ".search.index", ".timer.cache"}
+
+// Adds any missing rules to the .gitignore of the logs dir, creating it if need be, such that the files kept alongside
+// the logs are not left untracked in the project's repo. Returns its path.
+func EnsureLogsGitignore(root string) (string, error) {
+	f := filepath.Join(LogsDir(root), logsGitignoreFile)
+	text, err := ReadFile(f)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	var existing []string
+	for _, line := range strings.Split(text, "\n") {
+		existing = append(existing, strings.TrimSpace(line))
+	}
+	var missing []string
+	for _, rule := range logsGitignoreRules {
+		if !containsString(existing, rule) {
+			missing = append(missing, rule)
+		}
+	}
+	if len(missing) == 0 {
+		return f, nil
+	}
+	if len(text) > 0 && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	text += strings.Join(missing, "\n") + "\n"
+	return f, WriteFileAtomic(f, []byte(text), 0644)
+}
+
+// Updates to the logs made by this process, committed by CommitPendingChanges if git auto-commit is enabled
+var pendingChanges struct {
+	root    string
+	journal []*protos.JournalEntry
+}
+
+func recordPendingChange(root string, entry *protos.JournalEntry) {
+	pendingChanges.root = root
+	pendingChanges.journal = append(pendingChanges.journal, entry)
+}
+
+// Runs git within dir, returning its standard output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return "", fmt.Errorf("git %s failed\n%s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s failed\n%s", args[0], err.Error())
+	}
+	return stdout.String(), nil
+}
+
+// Commits the specified paths within the git repo containing dir, leaving any other staged changes uncommitted.
+// Paths that neither exist nor are tracked are ignored. Returns false if there were no changes to commit.
+func GitCommit(dir string, paths []string, message string) (bool, error) {
+	if out, err := runGit(dir, "rev-parse", "--is-inside-work-tree"); err != nil || strings.TrimSpace(out) != "true" {
+		return false, fmt.Errorf("%s is not in a git repo", dir)
+	}
+	var existing []string
+	for _, p := range paths {
+		// Paths are relative to the working directory rather than dir
+		p, err := filepath.Abs(p)
+		if err != nil {
+			return false, err
+		}
+		if exists, err := FileExists(p); err != nil {
+			return false, err
+		} else if exists {
+			existing = append(existing, p)
+		} else if tracked, err := runGit(dir, "ls-files", "--", p); err != nil {
+			return false, err
+		} else if len(tracked) > 0 {
+			existing = append(existing, p)
+		}
+	}
+	if len(existing) == 0 {
+		return false, nil
+	}
+	if _, err := runGit(dir, append([]string{"add", "-A", "--"}, existing...)...); err != nil {
+		return false, err
+	}
+	if changed, err := runGit(dir, append([]string{"diff", "--cached", "--name-only", "--"}, existing...)...); err != nil {
+		return false, err
+	} else if len(changed) == 0 {
+		return false, nil
+	}
+	if _, err := runGit(dir, append([]string{"commit", "-q", "-m", message, "--"}, existing...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Lists files within the logs dir with uncommitted changes, relative to the logs dir
+func GitPendingLogFiles(root string) ([]string, error) {
+	if _, err := runGit(root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("%s is not in a git repo", root)
+	}
+	out, err := runGit(LogsDir(root), "status", "--porcelain", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := runGit(LogsDir(root), "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.TrimSpace(prefix)
+	var files []string
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) < 4 {
+			continue
+		}
+		status, file := fields[i][:2], strings.TrimPrefix(fields[i][3:], prefix)
+		// Renames are followed by the original path
+		if status[0] == 'R' || status[0] == 'C' {
+			i++
+		}
+		// Skip backups, locks and caches
+		if isHiddenPath(file) {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Whether a path is hidden, other than the .gitignore of the logs dir
+func isHiddenPath(p string) bool {
+	if p == logsGitignoreFile {
+		return false
+	}
+	for _, name := range strings.Split(p, "/") {
+		if strings.HasPrefix(name, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the paths, relative to the logs dir, of the details file and attachments of a log entry
+func logEntryFiles(entry *protos.BuildLogEntry) []string {
+	var files []string
+	if len(entry.DetailsFile) > 0 {
+		files = append(files, LogDetailsRelativePath(entry.DetailsFile))
+	}
+	for _, a := range entry.Attachment {
+		files = append(files, a.File)
+	}
+	return files
+}
+
+// Returns the paths, relative to the logs dir, of details files and attachments added, moved or deleted by a change
+func journalChangeFiles(root string, change *protos.JournalChange) ([]string, error) {
+	var files []string
+	if change.After == nil {
+		// Files of a removed log entry are left in place unless moved to another log entry
+		for _, f := range logEntryFiles(change.Before) {
+			if exists, err := FileExists(filepath.Join(LogsDir(root), filepath.FromSlash(f))); err != nil {
+				return nil, err
+			} else if !exists {
+				files = append(files, f)
+			}
+		}
+		return files, nil
+	}
+	before := map[string]bool{}
+	if change.Before != nil {
+		before[LogDetailsRelativePath(change.Before.DetailsFile)] = true
+		for _, a := range change.Before.Attachment {
+			before[a.File] = true
+		}
+	}
+	if d := LogDetailsRelativePath(change.After.DetailsFile); len(d) > 0 && !before[d] {
+		files = append(files, d)
+		if change.Before != nil && len(change.Before.DetailsFile) > 0 {
+			files = append(files, LogDetailsRelativePath(change.Before.DetailsFile))
+		}
+	}
+	moved := map[string]bool{}
+	for _, a := range change.After.Attachment {
+		if !before[a.File] {
+			files = append(files, a.File)
+			moved[a.Sha256] = true
+		}
+	}
+	if change.Before != nil {
+		for _, a := range change.Before.Attachment {
+			if moved[a.Sha256] {
+				files = append(files, a.File)
+			}
+		}
+	}
+	return files, nil
+}
+
+func logEntrySummary(entry *protos.BuildLogEntry) string {
+	return fmt.Sprintf("%s %s: %s (%s)", entry.Date, entry.Assembly, entry.Title, FormatDurationMin(LogEntryMinutes(entry)))
+}
+
+// Whether a journal entry added a log entry with the same assembly and title as entry
+func entryAdded(journal *protos.JournalEntry, entry *protos.BuildLogEntry) bool {
+	for _, change := range journal.Change {
+		if change.Before == nil && change.After.Assembly == entry.Assembly && change.After.Title == entry.Title {
+			return true
+		}
+	}
+	return false
+}
+
+// Generates a commit message describing updates to the logs by the date, assembly, title and duration of each log
+// entry affected
+func LogCommitMessage(journal []*protos.JournalEntry) string {
+	var summaries, commands []string
+	seen := map[string]bool{}
+	for _, entry := range journal {
+		if len(entry.Command) > 0 {
+			commands = append(commands, entry.Command)
+		}
+		// A log entry moved to another date is removed and added under a new ID
+		moved := map[string]string{}
+		for _, change := range entry.Change {
+			if change.After == nil {
+				moved[change.Before.Assembly+"\x00"+change.Before.Title] = change.Before.Date
+			}
+		}
+		for _, change := range entry.Change {
+			var summary string
+			if change.After == nil {
+				if !entryAdded(entry, change.Before) {
+					summary = "Remove " + logEntrySummary(change.Before)
+				}
+			} else if from, exists := moved[change.After.Assembly+"\x00"+change.After.Title]; exists && change.Before == nil {
+				summary = logEntrySummary(change.After) + ", moved from " + from
+			} else {
+				summary = logEntrySummary(change.After)
+			}
+			if len(summary) == 0 {
+				continue
+			}
+			if !seen[summary] {
+				seen[summary] = true
+				summaries = append(summaries, summary)
+			}
+		}
+	}
+	var subject string
+	var body []string
+	if len(summaries) == 0 {
+		subject = "Update build log"
+	} else if len(summaries) == 1 {
+		subject = summaries[0]
+	} else {
+		subject = fmt.Sprintf("Update %d log entries", len(summaries))
+		body = append(body, summaries...)
+	}
+	if len(commands) > 0 {
+		if len(body) > 0 {
+			body = append(body, "")
+		}
+		body = append(body, commands...)
+	}
+	if len(body) == 0 {
+		return subject
+	}
+	return subject + "\n\n" + strings.Join(body, "\n")
+}
+
+// Generates a commit message for uncommitted files within the logs dir, given relative to the logs dir, describing the
+// log entries whose details files or attachments changed
+func SyncCommitMessage(logs []*protos.BuildLogEntry, files []string) string {
+	changed := map[string]bool{}
+	for _, f := range files {
+		changed[f] = true
+	}
+	var summaries []string
+	for _, entry := range logs {
+		affected := changed[LogDetailsRelativePath(entry.DetailsFile)]
+		for _, a := range entry.Attachment {
+			affected = affected || changed[a.File]
+		}
+		if affected {
+			summaries = append(summaries, logEntrySummary(entry))
+		}
+	}
+	switch len(summaries) {
+	case 0:
+		return "Update build log"
+	case 1:
+		return "Update details of " + summaries[0]
+	default:
+		return fmt.Sprintf("Update details of %d log entries\n\n%s", len(summaries), strings.Join(summaries, "\n"))
+	}
+}
+
+// Commits the updates to the logs made by this process, if the project has enabled git auto-commit
+func CommitPendingChanges() error {
+	if len(pendingChanges.journal) == 0 {
+		return nil
+	}
+	root := pendingChanges.root
+	config, err := ReadProjectConfig(root)
+	if err != nil {
+		return err
+	}
+	if !config.GitAutoCommit {
+		return nil
+	}
+	gitignore, err := EnsureLogsGitignore(root)
+	if err != nil {
+		return err
+	}
+	paths := []string{LogsPath(root), JournalPath(LogsPath(root)), gitignore}
+	for _, entry := range pendingChanges.journal {
+		for _, change := range entry.Change {
+			files, err := journalChangeFiles(root, change)
+			if err != nil {
+				return err
+			}
+			for _, f := range files {
+				paths = append(paths, filepath.Join(LogsDir(root), filepath.FromSlash(f)))
+			}
+		}
+	}
+	committed, err := GitCommit(root, paths, LogCommitMessage(pendingChanges.journal))
+	if err != nil {
+		return fmt.Errorf("Could not commit changes to the logs, commit them with 'sync' or disable git_auto_commit in %s\n%s", ConfigFile, err.Error())
+	}
+	if committed {
+		fmt.Println("Committed changes to the logs")
+	}
+	pendingChanges.journal = nil
+	return nil
+}
@@ -0,0 +1,211 @@
+package buildlog
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Creates a git repo containing a project with an empty logs dir, returning the project root
+func testGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Builder"},
+		{"config", "user.email", "builder@example.com"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := runGit(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(LogsDir(root), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func writeTestFile(t *testing.T, f string, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitPendingLogFiles(t *testing.T) {
+	root := testGitRepo(t)
+	logsDir := LogsDir(root)
+	writeTestFile(t, filepath.Join(logsDir, "buildlog.textproto"), "")
+	writeTestFile(t, filepath.Join(logsDir, "2024-Mar", "2024-Mar-01.md"), "# Notes\n")
+	writeTestFile(t, filepath.Join(logsDir, ".backups", "buildlog.textproto.1"), "")
+	writeTestFile(t, filepath.Join(logsDir, ".buildlog.textproto.lock"), "")
+	writeTestFile(t, filepath.Join(root, "README.md"), "")
+	if _, err := EnsureLogsGitignore(root); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := GitPendingLogFiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	expected := []string{".gitignore", "2024-Mar/2024-Mar-01.md", "buildlog.textproto"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Pending files are %v, expected %v", files, expected)
+	}
+
+	if _, err := GitCommit(root, []string{logsDir}, "Add logs"); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := GitPendingLogFiles(root); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 0 {
+		t.Errorf("Pending files are %v after committing the logs dir, expected none", files)
+	}
+	if out, err := runGit(root, "status", "--porcelain"); err != nil {
+		t.Fatal(err)
+	} else if strings.TrimSpace(out) != "?? README.md" {
+		t.Errorf("Status after committing the logs dir is %q, expected only README.md untracked", out)
+	}
+
+	if _, err := GitPendingLogFiles(t.TempDir()); err == nil {
+		t.Error("Listing pending files outside of a git repo succeeded, expected an error")
+	}
+}
+
+func TestGitCommit(t *testing.T) {
+	root := testGitRepo(t)
+	logs := filepath.Join(LogsDir(root), "buildlog.textproto")
+	details := filepath.Join(LogsDir(root), "2024-Mar", "2024-Mar-01.md")
+	other := filepath.Join(root, "notes.txt")
+	writeTestFile(t, logs, "log_entry: <>\n")
+	writeTestFile(t, other, "Staged but not part of the commit\n")
+	if _, err := runGit(root, "add", other); err != nil {
+		t.Fatal(err)
+	}
+
+	// The details file does not exist and is not tracked, and so is left out
+	committed, err := GitCommit(root, []string{logs, details}, "Add log entry\n\nccub log")
+	if err != nil {
+		t.Fatal(err)
+	} else if !committed {
+		t.Fatal("Nothing was committed")
+	}
+	if out, err := runGit(root, "log", "-1", "--format=%B"); err != nil {
+		t.Fatal(err)
+	} else if strings.TrimSpace(out) != "Add log entry\n\nccub log" {
+		t.Errorf("Commit message is %q", out)
+	}
+	if out, err := runGit(root, "show", "--name-only", "--format=", "HEAD"); err != nil {
+		t.Fatal(err)
+	} else if strings.TrimSpace(out) != "log/buildlog.textproto" {
+		t.Errorf("Committed %q, expected only log/buildlog.textproto", out)
+	}
+	if out, err := runGit(root, "diff", "--cached", "--name-only"); err != nil {
+		t.Fatal(err)
+	} else if strings.TrimSpace(out) != "notes.txt" {
+		t.Errorf("Staged %q after committing, expected notes.txt to remain staged", out)
+	}
+
+	if committed, err := GitCommit(root, []string{logs}, "No changes"); err != nil {
+		t.Fatal(err)
+	} else if committed {
+		t.Error("Committed a file without changes")
+	}
+
+	// Removing a tracked file is committed
+	if err := os.Remove(logs); err != nil {
+		t.Fatal(err)
+	}
+	if committed, err := GitCommit(root, []string{logs}, "Remove logs"); err != nil {
+		t.Fatal(err)
+	} else if !committed {
+		t.Error("Removal of a tracked file was not committed")
+	}
+
+	if _, err := GitCommit(t.TempDir(), []string{logs}, "Outside of a repo"); err == nil {
+		t.Error("Committing outside of a git repo succeeded, expected an error")
+	}
+}
+
+func TestLogCommitMessage(t *testing.T) {
+	added := &protos.BuildLogEntry{
+		Id:         "2024-Mar-01",
+		Date:       "2024-Mar-01",
+		Assembly:   "fuselage",
+		Title:      "Rivet longerons",
+		WorkPeriod: []*protos.TimePeriod{{DurationMin: 90}},
+	}
+	changedBefore := &protos.BuildLogEntry{
+		Id:         "2024-Mar-02",
+		Date:       "2024-Mar-02",
+		Assembly:   "left wing",
+		Title:      "Drill skins",
+		WorkPeriod: []*protos.TimePeriod{{DurationMin: 60}},
+	}
+	changedAfter := &protos.BuildLogEntry{
+		Id:         "2024-Mar-02",
+		Date:       "2024-Mar-02",
+		Assembly:   "left wing",
+		Title:      "Drill skins",
+		WorkPeriod: []*protos.TimePeriod{{DurationMin: 60}, {DurationMin: 30}},
+	}
+	movedFrom := &protos.BuildLogEntry{Id: "2024-Mar-03", Date: "2024-Mar-03", Assembly: "gear", Title: "Mount axles"}
+	movedTo := &protos.BuildLogEntry{Id: "2024-Mar-04", Date: "2024-Mar-04", Assembly: "gear", Title: "Mount axles"}
+	removed := &protos.BuildLogEntry{Id: "2024-Mar-05", Date: "2024-Mar-05", Assembly: "skin", Title: "Cover"}
+
+	tests := []struct {
+		journal  []*protos.JournalEntry
+		expected string
+	}{
+		{
+			[]*protos.JournalEntry{{Change: []*protos.JournalChange{{After: added}}}},
+			"2024-Mar-01 fuselage: Rivet longerons (1h30m)",
+		},
+		{
+			[]*protos.JournalEntry{{Command: "ccub log fuselage", Change: []*protos.JournalChange{{After: added}}}},
+			"2024-Mar-01 fuselage: Rivet longerons (1h30m)\n\nccub log fuselage",
+		},
+		{
+			[]*protos.JournalEntry{
+				{Command: "ccub start fuselage", Change: []*protos.JournalChange{{After: added}}},
+				{Command: "ccub log left wing", Change: []*protos.JournalChange{{Before: changedBefore, After: changedAfter}}},
+				{Command: "ccub stop", Change: []*protos.JournalChange{{After: added}}},
+			},
+			"Update 2 log entries\n\n" +
+				"2024-Mar-01 fuselage: Rivet longerons (1h30m)\n" +
+				"2024-Mar-02 left wing: Drill skins (1h30m)\n\n" +
+				"ccub start fuselage\nccub log left wing\nccub stop",
+		},
+		{
+			[]*protos.JournalEntry{{Change: []*protos.JournalChange{{Before: movedFrom}, {After: movedTo}}}},
+			"2024-Mar-04 gear: Mount axles (0m), moved from 2024-Mar-03",
+		},
+		{
+			[]*protos.JournalEntry{{Change: []*protos.JournalChange{{Before: removed}}}},
+			"Remove 2024-Mar-05 skin: Cover (0m)",
+		},
+		{
+			[]*protos.JournalEntry{{Undone: []uint32{1}}},
+			"Update build log",
+		},
+	}
+	for _, test := range tests {
+		if msg := LogCommitMessage(test.journal); msg != test.expected {
+			t.Errorf("Commit message is %q, expected %q", msg, test.expected)
+		}
+	}
+}
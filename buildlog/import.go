@@ -0,0 +1,268 @@
+package buildlog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+// How imported log entries are merged with existing log entries for the same assembly on the same day
+const (
+	// Adds the imported work periods that do not overlap work periods already logged by the same builder
+	ImportMerge = "merge"
+	// Replaces the existing log entry, as by UpsertLogUpdater
+	ImportReplace = "replace"
+	// Leaves the existing log entry unchanged
+	ImportSkip = "skip"
+)
+
+var ImportConflictModes = []string{ImportMerge, ImportReplace, ImportSkip}
+
+// A work period read for import, prior to resolving its assembly, subassemblies and builder against the project config
+type ImportRecord struct {
+	// Where the record was read from, e.g., "Line 12", for error messages
+	Source      string
+	Start       time.Time
+	End         time.Time
+	Assembly    string
+	Subassembly string
+	Title       string
+	Tags        []string
+	Builder     string
+	// Reason the record has no work period to import, such as an all-day event, if it is to be skipped
+	Skip string
+}
+
+// A record left out of an import, along with the reason why
+type SkippedImportRecord struct {
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+}
+
+type ImportOptions struct {
+	// Column of a CSV file from which to read each field, by field name
+	Columns map[string]string
+	// Time zone of times that do not specify one
+	Location *time.Location
+}
+
+// Applied to imported work periods that do not specify their own
+type ImportDefaults struct {
+	Assembly string
+	Builder  string
+	Helpers  []string
+}
+
+type ImportSummary struct {
+	Added              int `json:"added"`
+	Merged             int `json:"merged"`
+	Replaced           int `json:"replaced"`
+	Skipped            int `json:"skipped"`
+	Periods            int `json:"periods"`
+	OverlappingPeriods int `json:"overlapping_periods"`
+	// Log entries added, for which details files are to be created
+	added []*protos.BuildLogEntry
+}
+
+func (s *ImportSummary) AddedEntries() []*protos.BuildLogEntry {
+	return s.added
+}
+
+// Returns the first tag naming an assembly exactly, by name or alias, along with the remaining tags
+func assemblyFromTags(config *protos.ProjectConfig, tags []string) (string, []string) {
+	for i, t := range tags {
+		for _, item := range assemblyItems(config) {
+			for _, n := range append([]string{item.name}, item.aliases...) {
+				if strings.EqualFold(n, t) {
+					return item.name, append(append([]string{}, tags[:i]...), tags[i+1:]...)
+				}
+			}
+		}
+	}
+	return "", tags
+}
+
+// Converts imported work periods to log entries, one per assembly per day. Work periods that cross midnight are split
+// at each midnight. A record without an assembly is assigned the first of its tags that names an assembly, or else
+// the default. Records without a work period to import, including work periods of no length, are returned as skipped.
+func ImportLogEntries(config *protos.ProjectConfig, records []*ImportRecord, defaults ImportDefaults) ([]*protos.BuildLogEntry, []SkippedImportRecord, error) {
+	var entries []*protos.BuildLogEntry
+	var skipped []SkippedImportRecord
+	byKey := map[string]*protos.BuildLogEntry{}
+	for _, r := range records {
+		fail := func(err error) error {
+			return fmt.Errorf("%s: %s", r.Source, err.Error())
+		}
+		if len(r.Skip) > 0 {
+			skipped = append(skipped, SkippedImportRecord{r.Source, r.Skip})
+			continue
+		}
+		if r.End.Before(r.Start) {
+			return nil, nil, fail(fmt.Errorf("Work period must end after it starts at %s", r.Start.Format(time.RFC3339)))
+		}
+		// Such as after a stop immediately following a start
+		if !r.End.Truncate(time.Minute).After(r.Start.Truncate(time.Minute)) {
+			skipped = append(skipped, SkippedImportRecord{r.Source, fmt.Sprintf("Work period at %s has no length", r.Start.Format(time.RFC3339))})
+			continue
+		}
+		assembly, tags := r.Assembly, r.Tags
+		if len(assembly) == 0 {
+			assembly, tags = assemblyFromTags(config, r.Tags)
+		}
+		if len(assembly) == 0 {
+			assembly = defaults.Assembly
+		}
+		if len(assembly) == 0 {
+			return nil, nil, fail(errors.New("No assembly given, see -assembly"))
+		}
+		assembly, err := ParseAssemblyArg(config, assembly)
+		if err != nil {
+			return nil, nil, fail(err)
+		}
+		var subassemblies []string
+		if len(r.Subassembly) > 0 {
+			if subassemblies, err = ParseSubassemblyArg(config, assembly, r.Subassembly); err != nil {
+				return nil, nil, fail(err)
+			}
+		}
+		builder := defaults.Builder
+		if len(r.Builder) > 0 {
+			if builder, err = ParseBuilderArg(config, r.Builder); err != nil {
+				return nil, nil, fail(err)
+			}
+		}
+
+		start := r.Start.Truncate(time.Minute)
+		for segStart := start; segStart.Before(r.End); segStart = startOfNextDay(segStart) {
+			segEnd := r.End
+			if midnight := startOfNextDay(segStart); segEnd.After(midnight) {
+				segEnd = midnight
+			}
+			wp := NewWorkPeriod(segStart, segEnd)
+			AttributeWorkPeriod(wp, builder, defaults.Helpers)
+			date := FormatDateForLog(segStart)
+			key := date + "/" + assembly
+			entry, exists := byKey[key]
+			if !exists {
+				entry = &protos.BuildLogEntry{Assembly: assembly, Date: date}
+				byKey[key] = entry
+				entries = append(entries, entry)
+			}
+			entry.WorkPeriod = append(entry.WorkPeriod, wp)
+			if len(entry.Title) == 0 {
+				entry.Title = r.Title
+			}
+			entry.Subassembly = appendMissing(entry.Subassembly, subassemblies...)
+			entry.Tags = appendMissing(entry.Tags, tags...)
+		}
+	}
+	for _, entry := range entries {
+		sortWorkPeriods(entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		it, _ := ParseDateOfLog(entries[i])
+		jt, _ := ParseDateOfLog(entries[j])
+		return it.Before(jt)
+	})
+	return entries, skipped, nil
+}
+
+func appendMissing(s []string, items ...string) []string {
+	for _, v := range items {
+		if !containsString(s, v) {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+func sortWorkPeriods(entry *protos.BuildLogEntry) {
+	sort.SliceStable(entry.WorkPeriod, func(i, j int) bool {
+		si, _ := WorkPeriodStart(entry, entry.WorkPeriod[i])
+		sj, _ := WorkPeriodStart(entry, entry.WorkPeriod[j])
+		return si.Before(sj)
+	})
+}
+
+// Whether a work period overlaps one already logged by the same builder
+func overlapsLoggedWork(logs []*protos.BuildLogEntry, entry *protos.BuildLogEntry, wp *protos.TimePeriod) bool {
+	start, err := WorkPeriodStart(entry, wp)
+	if err != nil {
+		return false
+	}
+	end, err := WorkPeriodEnd(entry, wp)
+	if err != nil {
+		return false
+	}
+	for _, ref := range BuilderWorkPeriods(logs, wp.Builder) {
+		if ref.End.IsZero() {
+			continue
+		}
+		if start.Before(ref.End) && ref.Start.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds imported log entries to the logs, resolving each that conflicts with an existing log entry for the same
+// assembly on the same day as specified by conflict. When merging, work periods that overlap work already logged by
+// the same builder are left out, such that importing the same file again changes nothing.
+func ImportLogUpdater(entries []*protos.BuildLogEntry, conflict string, summary *ImportSummary) LogUpdater {
+	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		*summary = ImportSummary{}
+		for _, imported := range entries {
+			entry := proto.Clone(imported).(*protos.BuildLogEntry)
+			date, err := ParseDateOfLog(entry)
+			if err != nil {
+				return nil, err
+			}
+			if conflict == ImportMerge {
+				var periods []*protos.TimePeriod
+				for _, wp := range entry.WorkPeriod {
+					if overlapsLoggedWork(logs, entry, wp) {
+						summary.OverlappingPeriods++
+					} else {
+						periods = append(periods, wp)
+					}
+				}
+				if len(periods) == 0 {
+					summary.Skipped++
+					continue
+				}
+				entry.WorkPeriod = periods
+			}
+			exists, index := FindLogEntry(logs, date, entry.Assembly)
+			switch {
+			case !exists:
+				logs = AppendLogEntry(logs, entry)
+				summary.added = append(summary.added, entry)
+				summary.Added++
+			case conflict == ImportSkip:
+				summary.Skipped++
+				continue
+			case conflict == ImportReplace:
+				if logs, err = UpsertLogUpdater(entry)(logs); err != nil {
+					return nil, err
+				}
+				summary.Replaced++
+			default:
+				existing := logs[index]
+				existing.WorkPeriod = append(existing.WorkPeriod, entry.WorkPeriod...)
+				if len(existing.Title) == 0 {
+					existing.Title = entry.Title
+				}
+				existing.Subassembly = appendMissing(existing.Subassembly, entry.Subassembly...)
+				existing.Tags = appendMissing(existing.Tags, entry.Tags...)
+				summary.Merged++
+			}
+			summary.Periods += len(entry.WorkPeriod)
+		}
+		return logs, nil
+	}
+}
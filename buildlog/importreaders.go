@@ -0,0 +1,475 @@
+package buildlog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reads work periods from a file exported by another time tracker or spreadsheet
+type ImportReader func(r io.Reader, opts *ImportOptions) ([]*ImportRecord, error)
+
+var importReaders = map[string]ImportReader{
+	"csv":      readCSVImport,
+	"toggl":    readTrackerImport,
+	"clockify": readTrackerImport,
+	"ics":      readICSImport,
+}
+
+// Fields of an ImportRecord that may be read from a column of a CSV file. A work period is given either by start and
+// end, optionally along with date and end_date, by start and duration, or by time, e.g., 9am-12pm.
+var ImportFields = []string{"date", "start", "end", "end_date", "duration", "time", "assembly", "subassembly", "title", "tags", "builder"}
+
+// Columns of the CSV exports of Toggl Track and Clockify, which differ only in capitalization
+var trackerColumns = map[string]string{
+	"date":        "Start date",
+	"start":       "Start time",
+	"end_date":    "End date",
+	"end":         "End time",
+	"assembly":    "Project",
+	"subassembly": "Task",
+	"title":       "Description",
+	"tags":        "Tags",
+	"builder":     "User",
+}
+
+var importDateLayouts = []string{"2006-01-02", DateLayout, "1/2/2006", "2006/1/2", "Jan 2, 2006", "2 Jan 2006", "20060102"}
+
+var importTimestampLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+var importClockLayouts = []string{"15:04", "15:04:05", "3:04PM", "3:04:05PM", "3PM"}
+
+func ImportFormats() []string {
+	var formats []string
+	for f := range importReaders {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Returns the format in which to read a file unless specified otherwise, going by its extension
+func ImportFormatOfFile(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".ics") {
+		return "ics"
+	}
+	return "csv"
+}
+
+func ReadImport(format string, r io.Reader, opts *ImportOptions) ([]*ImportRecord, error) {
+	read, ok := importReaders[format]
+	if !ok {
+		return nil, fmt.Errorf("Import format must be one of:\n  %s", strings.Join(ImportFormats(), "\n  "))
+	}
+	return read(r, opts)
+}
+
+// Parses a column mapping of the form field=Header,..., e.g., "title=Description,date=Day"
+func ParseImportColumnsArg(arg string) (map[string]string, error) {
+	columns := map[string]string{}
+	for _, v := range strings.Split(arg, ",") {
+		kv := strings.SplitN(v, "=", 2)
+		field := strings.ToLower(strings.TrimSpace(kv[0]))
+		if len(kv) != 2 || len(strings.TrimSpace(kv[1])) == 0 {
+			return nil, fmt.Errorf("Bad column mapping \"%s\", expected FIELD=COLUMN", v)
+		}
+		if !containsString(ImportFields, field) {
+			return nil, fmt.Errorf("Unknown field %s, must be one of:\n  %s", field, strings.Join(ImportFields, "\n  "))
+		}
+		columns[field] = strings.TrimSpace(kv[1])
+	}
+	return columns, nil
+}
+
+func readCSVImport(r io.Reader, opts *ImportOptions) ([]*ImportRecord, error) {
+	return readMappedCSV(r, opts, nil)
+}
+
+func readTrackerImport(r io.Reader, opts *ImportOptions) ([]*ImportRecord, error) {
+	return readMappedCSV(r, opts, trackerColumns)
+}
+
+// Reads a CSV file with a header row, in which each field is read from the column of the same name unless mapped to
+// another by opts.Columns or else the defaults
+func readMappedCSV(r io.Reader, opts *ImportOptions, defaults map[string]string) ([]*ImportRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	} else if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		// Spreadsheets commonly begin the file with a byte order mark
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	columns := map[string]int{}
+	for _, field := range ImportFields {
+		name := field
+		if c, ok := opts.Columns[field]; ok {
+			name = c
+		} else if c, ok := defaults[field]; ok {
+			name = c
+		}
+		if i, ok := index[strings.ToLower(name)]; ok {
+			columns[field] = i
+		} else if _, ok := opts.Columns[field]; ok {
+			return nil, fmt.Errorf("No column %s in CSV header", name)
+		}
+	}
+	_, hasTime := columns["time"]
+	_, hasStart := columns["start"]
+	if !hasTime && !hasStart {
+		return nil, errors.New("CSV file must have either a time or a start column, see -columns")
+	}
+
+	var records []*ImportRecord
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		if len(strings.Join(row, "")) == 0 {
+			continue
+		}
+		source := fmt.Sprintf("Line %d", line)
+		periods, skip, err := csvRowPeriods(value, opts.Location)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", source, err.Error())
+		}
+		if len(skip) > 0 {
+			records = append(records, &ImportRecord{Source: source, Skip: skip})
+			continue
+		}
+		for _, p := range periods {
+			records = append(records, &ImportRecord{
+				Source:      source,
+				Start:       p[0],
+				End:         p[1],
+				Assembly:    value("assembly"),
+				Subassembly: value("subassembly"),
+				Title:       value("title"),
+				Tags:        splitImportList(value("tags")),
+				Builder:     value("builder"),
+			})
+		}
+	}
+	return records, nil
+}
+
+// Returns the start and end of each work period of a CSV row, or else the reason the row has none to import: work
+// periods still ongoing, without an end or a duration, and log entries without any work periods, as both are exported
+func csvRowPeriods(value func(string) string, loc *time.Location) ([][2]time.Time, string, error) {
+	var date time.Time
+	if d := value("date"); len(d) > 0 {
+		var err error
+		if date, err = parseImportDate(d, loc); err != nil {
+			return nil, "", err
+		}
+	}
+	if t := value("time"); len(t) > 0 {
+		if date.IsZero() {
+			return nil, "", errors.New("A time column requires a date column")
+		}
+		var periods [][2]time.Time
+		for _, v := range strings.Split(t, ",") {
+			s := strings.Split(v, "-")
+			if len(s) != 2 {
+				return nil, "", errors.New("Time period must consist of both a start time and an end time")
+			}
+			start, _, err := parseImportTime(s[0], date, loc)
+			if err != nil {
+				return nil, "", err
+			}
+			end, _, err := parseImportTime(s[1], date, loc)
+			if err != nil {
+				return nil, "", err
+			}
+			periods = append(periods, [2]time.Time{start, end})
+		}
+		return periods, "", nil
+	}
+	if len(value("start")) == 0 {
+		if len(value("end")) == 0 && len(value("duration")) == 0 {
+			return nil, "No work period", nil
+		}
+		return nil, "", errors.New("Missing start time")
+	}
+	start, _, err := parseImportTime(value("start"), date, loc)
+	if err != nil {
+		return nil, "", err
+	}
+	var end time.Time
+	if e := value("end"); len(e) > 0 {
+		endDate := date
+		if d := value("end_date"); len(d) > 0 {
+			if endDate, err = parseImportDate(d, loc); err != nil {
+				return nil, "", err
+			}
+		}
+		var dated bool
+		if end, dated, err = parseImportTime(e, endDate, loc); err != nil {
+			return nil, "", err
+		}
+		// A time of day without a date of its own that is not after the start is taken to be on the following day
+		if !dated && len(value("end_date")) == 0 && !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+	} else if d := value("duration"); len(d) > 0 {
+		dur, err := parseImportDuration(d)
+		if err != nil {
+			return nil, "", err
+		}
+		end = start.Add(dur)
+	} else {
+		return nil, fmt.Sprintf("Work period starting at %s is ongoing", start.Format(time.RFC3339)), nil
+	}
+	return [][2]time.Time{{start, end}}, "", nil
+}
+
+func parseImportDate(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Bad date %s", s)
+}
+
+// Parses either a full timestamp, or a time of day on date. Returns whether the timestamp included a date.
+func parseImportTime(s string, date time.Time, loc *time.Location) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range importTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t.In(loc), true, nil
+		}
+	}
+	if date.IsZero() {
+		return time.Time{}, false, fmt.Errorf("Bad time %s, expected a date and time or a date column", s)
+	}
+	// Accepts 9am, 9:30 AM, 09:30:00 and 21:30
+	clock := strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	for _, layout := range importClockLayouts {
+		if t, err := time.Parse(layout, clock); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), false, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("Bad time %s", s)
+}
+
+// Parses a duration given as h:mm, h:mm:ss, decimal hours, or as by time.ParseDuration, e.g., 1h30m
+func parseImportDuration(s string) (time.Duration, error) {
+	if parts := strings.Split(s, ":"); len(parts) == 2 || len(parts) == 3 {
+		var d time.Duration
+		units := []time.Duration{time.Hour, time.Minute, time.Second}
+		for i, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("Bad duration %s", s)
+			}
+			d += time.Duration(n) * units[i]
+		}
+		return d, nil
+	}
+	if h, err := strconv.ParseFloat(s, 64); err == nil && h >= 0 {
+		return time.Duration(h * float64(time.Hour)), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("Bad duration %s", s)
+}
+
+func splitImportList(s string) []string {
+	var items []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 && !containsString(items, v) {
+			items = append(items, v)
+		}
+	}
+	return items
+}
+
+type icsProperty struct {
+	params map[string]string
+	value  string
+}
+
+// Reads the events of an iCalendar file, taking the summary as the title and categories as tags. All-day events are
+// skipped since they do not record when work was done, as are events without an end or a duration.
+func readICSImport(r io.Reader, opts *ImportOptions) ([]*ImportRecord, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []*ImportRecord
+	var event map[string]icsProperty
+	n := 0
+	for _, line := range lines {
+		name, prop, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && prop.value == "VEVENT":
+			event = map[string]icsProperty{}
+			n++
+		case name == "END" && prop.value == "VEVENT" && event != nil:
+			record, err := icsEventRecord(event, opts.Location)
+			if err != nil {
+				return nil, fmt.Errorf("Event %d: %s", n, err.Error())
+			}
+			record.Source = fmt.Sprintf("Event %d", n)
+			records = append(records, record)
+			event = nil
+		case event != nil:
+			if _, exists := event[name]; !exists {
+				event[name] = prop
+			}
+		}
+	}
+	return records, nil
+}
+
+// Joins content lines folded onto continuation lines beginning with whitespace
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func parseICSLine(line string) (string, icsProperty, bool) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", icsProperty{}, false
+	}
+	parts := strings.Split(line[:i], ";")
+	prop := icsProperty{params: map[string]string{}, value: line[i+1:]}
+	for _, p := range parts[1:] {
+		if kv := strings.SplitN(p, "=", 2); len(kv) == 2 {
+			prop.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], "\"")
+		}
+	}
+	return strings.ToUpper(parts[0]), prop, true
+}
+
+var icsTextEscapes = strings.NewReplacer("\\\\", "\\", "\\,", ",", "\\;", ";", "\\n", "\n", "\\N", "\n")
+
+func icsEventRecord(event map[string]icsProperty, loc *time.Location) (*ImportRecord, error) {
+	dtstart, ok := event["DTSTART"]
+	if !ok {
+		return nil, errors.New("Missing DTSTART")
+	}
+	if dtstart.params["VALUE"] == "DATE" || len(dtstart.value) == len("20060102") {
+		return &ImportRecord{Skip: "All-day event"}, nil
+	}
+	start, err := parseICSTime(dtstart, loc)
+	if err != nil {
+		return nil, err
+	}
+	var end time.Time
+	if dtend, ok := event["DTEND"]; ok {
+		if end, err = parseICSTime(dtend, loc); err != nil {
+			return nil, err
+		}
+	} else if dur, ok := event["DURATION"]; ok {
+		d, err := parseICSDuration(dur.value)
+		if err != nil {
+			return nil, err
+		}
+		end = start.Add(d)
+	} else {
+		return &ImportRecord{Skip: fmt.Sprintf("Event starting at %s has no end", start.Format(time.RFC3339))}, nil
+	}
+	record := &ImportRecord{Start: start, End: end, Title: icsTextEscapes.Replace(event["SUMMARY"].value)}
+	if categories, ok := event["CATEGORIES"]; ok {
+		for _, c := range splitICSList(categories.value) {
+			if c = strings.TrimSpace(icsTextEscapes.Replace(c)); len(c) > 0 && !containsString(record.Tags, c) {
+				record.Tags = append(record.Tags, c)
+			}
+		}
+	}
+	return record, nil
+}
+
+// Splits a comma-separated list value, leaving escaped commas to be unescaped as part of the items
+func splitICSList(s string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == ',' {
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+func parseICSTime(prop icsProperty, loc *time.Location) (time.Time, error) {
+	v := prop.value
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse("20060102T150405Z", v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Bad time %s", v)
+		}
+		return t.In(loc), nil
+	}
+	eventLoc := loc
+	if tzid, ok := prop.params["TZID"]; ok {
+		var err error
+		if eventLoc, err = LoadTimeZone(tzid); err != nil {
+			return time.Time{}, err
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", v, eventLoc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Bad time %s", v)
+	}
+	return t.In(loc), nil
+}
+
+var icsDurationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// Parses an iCalendar duration, e.g., PT1H30M
+func parseICSDuration(s string) (time.Duration, error) {
+	submatches := icsDurationPattern.FindStringSubmatch(strings.TrimPrefix(s, "+"))
+	if submatches == nil {
+		return 0, fmt.Errorf("Bad duration %s", s)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if n, err := strconv.Atoi(submatches[i+1]); err == nil {
+			d += time.Duration(n) * unit
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,195 @@
+package buildlog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testImportRecord struct {
+	start string
+	end   string
+	skip  string
+}
+
+func checkImportRecords(t *testing.T, records []*ImportRecord, expected []testImportRecord) {
+	t.Helper()
+	if len(records) != len(expected) {
+		t.Fatalf("Got %d records, expected %d", len(records), len(expected))
+	}
+	for i, e := range expected {
+		r := records[i]
+		if len(e.skip) > 0 {
+			if r.Skip != e.skip {
+				t.Errorf("%s is skipped as %q, expected %q", r.Source, r.Skip, e.skip)
+			}
+			continue
+		}
+		if len(r.Skip) > 0 {
+			t.Errorf("%s is skipped as %q, expected a work period", r.Source, r.Skip)
+			continue
+		}
+		if start := r.Start.Format(time.RFC3339); start != e.start {
+			t.Errorf("%s starts at %s, expected %s", r.Source, start, e.start)
+		}
+		if end := r.End.Format(time.RFC3339); end != e.end {
+			t.Errorf("%s ends at %s, expected %s", r.Source, end, e.end)
+		}
+	}
+}
+
+func TestReadCSVImport(t *testing.T) {
+	csv := "\ufeffDate,Start,End,Duration,Assembly,Title,Tags\n" +
+		"2024-03-01,9:00,11:30,,fuselage,Rivet longerons,\"riveting, longerons\"\n" +
+		"2024-03-01,22:00,1:00,,fuselage,Late night,\n" +
+		"2024-03-02,2024-03-02T08:00,,90m,left wing,Drill skins,\n" +
+		"2024-03-03,10:00,,,left wing,Still going,\n" +
+		"2024-03-04,,,,left wing,Planning,\n" +
+		",,,,,,\n"
+	records, err := ReadImport("csv", strings.NewReader(csv), &ImportOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkImportRecords(t, records, []testImportRecord{
+		{start: "2024-03-01T09:00:00Z", end: "2024-03-01T11:30:00Z"},
+		{start: "2024-03-01T22:00:00Z", end: "2024-03-02T01:00:00Z"},
+		{start: "2024-03-02T08:00:00Z", end: "2024-03-02T09:30:00Z"},
+		{skip: "Work period starting at 2024-03-03T10:00:00Z is ongoing"},
+		{skip: "No work period"},
+	})
+	if r := records[0]; r.Source != "Line 2" || r.Assembly != "fuselage" || r.Title != "Rivet longerons" || !reflect.DeepEqual(r.Tags, []string{"riveting", "longerons"}) {
+		t.Errorf("First record is %+v", r)
+	}
+}
+
+func TestReadCSVImportTimeColumn(t *testing.T) {
+	csv := "Day,Hours,Part\n" +
+		"3/1/2024,\"9am-12pm,1pm-2:30pm\",fuselage\n"
+	columns, err := ParseImportColumnsArg("date=Day,time=Hours,assembly=Part")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadImport("csv", strings.NewReader(csv), &ImportOptions{Columns: columns, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkImportRecords(t, records, []testImportRecord{
+		{start: "2024-03-01T09:00:00Z", end: "2024-03-01T12:00:00Z"},
+		{start: "2024-03-01T13:00:00Z", end: "2024-03-01T14:30:00Z"},
+	})
+	if records[1].Assembly != "fuselage" {
+		t.Errorf("Second record has assembly %q, expected fuselage", records[1].Assembly)
+	}
+}
+
+func TestReadCSVImportErrors(t *testing.T) {
+	tests := []struct {
+		csv     string
+		columns map[string]string
+	}{
+		{"", nil},
+		{"Date,Title\n2024-03-01,Rivet\n", nil},
+		{"Date,Start\n2024-03-01,9:00\n", map[string]string{"end": "Finish"}},
+		{"Date,Start,End\nMarch first,9:00,10:00\n", nil},
+		{"Date,Start,End\n2024-03-01,,10:00\n", nil},
+		{"Time\n9am-10am\n", nil},
+	}
+	for _, test := range tests {
+		if _, err := ReadImport("csv", strings.NewReader(test.csv), &ImportOptions{Columns: test.columns, Location: time.UTC}); err == nil {
+			t.Errorf("Reading %q succeeded, expected an error", test.csv)
+		}
+	}
+	if _, err := ReadImport("xls", strings.NewReader(""), &ImportOptions{Location: time.UTC}); err == nil {
+		t.Error("Reading an unknown format succeeded, expected an error")
+	}
+}
+
+func TestReadTogglImport(t *testing.T) {
+	csv := "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n" +
+		"Craig,craig@example.com,,fuselage,longerons,Rivet longerons,No,2024-03-01,22:00:00,2024-03-02,01:30:00,03:30:00,riveting\n"
+	records, err := ReadImport("toggl", strings.NewReader(csv), &ImportOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkImportRecords(t, records, []testImportRecord{
+		{start: "2024-03-01T22:00:00Z", end: "2024-03-02T01:30:00Z"},
+	})
+	r := records[0]
+	if r.Builder != "Craig" || r.Assembly != "fuselage" || r.Subassembly != "longerons" || r.Title != "Rivet longerons" || !reflect.DeepEqual(r.Tags, []string{"riveting"}) {
+		t.Errorf("Record is %+v", r)
+	}
+}
+
+func TestReadICSImport(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART:20240301T160000Z",
+		"DTEND:20240301T183000Z",
+		"SUMMARY:Rivet longerons\\, upper",
+		"CATEGORIES:fuselage,riveting",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;TZID=America/Denver:20240302T090000",
+		"DURATION:PT1H30M",
+		"SUMMARY:Drill skins and",
+		"  deburr",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20240303",
+		"SUMMARY:Hangar day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20240304T160000Z",
+		"SUMMARY:Still going",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	records, err := ReadImport("ics", strings.NewReader(ics), &ImportOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkImportRecords(t, records, []testImportRecord{
+		{start: "2024-03-01T16:00:00Z", end: "2024-03-01T18:30:00Z"},
+		{start: "2024-03-02T16:00:00Z", end: "2024-03-02T17:30:00Z"},
+		{skip: "All-day event"},
+		{skip: "Event starting at 2024-03-04T16:00:00Z has no end"},
+	})
+	if r := records[0]; r.Source != "Event 1" || r.Title != "Rivet longerons, upper" || !reflect.DeepEqual(r.Tags, []string{"fuselage", "riveting"}) {
+		t.Errorf("First record is %+v", r)
+	}
+	if r := records[1]; r.Title != "Drill skins and deburr" {
+		t.Errorf("Second record has title %q, expected %q", r.Title, "Drill skins and deburr")
+	}
+	if _, err := ReadImport("ics", strings.NewReader("BEGIN:VEVENT\nSUMMARY:No start\nEND:VEVENT\n"), &ImportOptions{Location: time.UTC}); err == nil {
+		t.Error("Reading an event without DTSTART succeeded, expected an error")
+	}
+}
+
+func TestImportLogEntriesSkipsRecords(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	records := []*ImportRecord{
+		{Source: "Line 2", Skip: "No work period"},
+		{Source: "Line 3", Start: start, End: start.Add(30 * time.Second)},
+	}
+	entries, skipped, err := ImportLogEntries(nil, records, ImportDefaults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Got %d log entries, expected none", len(entries))
+	}
+	expected := []SkippedImportRecord{
+		{"Line 2", "No work period"},
+		{"Line 3", "Work period at 2024-03-01T09:00:00Z has no length"},
+	}
+	if !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("Skipped %v, expected %v", skipped, expected)
+	}
+
+	records = []*ImportRecord{{Source: "Line 4", Start: start, End: start.Add(-time.Hour)}}
+	if _, _, err := ImportLogEntries(nil, records, ImportDefaults{}); err == nil {
+		t.Error("Importing a work period ending before it starts succeeded, expected an error")
+	}
+}
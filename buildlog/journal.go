@@ -0,0 +1,333 @@
+package buildlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+const JournalFile = "journal.textproto"
+
+// Command line recorded in the journal for updates made by this process
+var journalCommand string
+
+func SetJournalCommand(argv []string) {
+	quoted := make([]string, len(argv))
+	for i, v := range argv {
+		if len(v) == 0 || strings.ContainsAny(v, " \t\n\"'\\") {
+			v = strconv.Quote(v)
+		}
+		quoted[i] = v
+	}
+	journalCommand = strings.Join(quoted, " ")
+}
+
+// Path of the journal for the logs metadata file f
+func JournalPath(f string) string {
+	return filepath.Join(filepath.Dir(f), JournalFile)
+}
+
+func ReadJournal(f string) (*protos.Journal, error) {
+	text, err := ReadFile(f)
+	if os.IsNotExist(err) {
+		return &protos.Journal{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	journal := protos.Journal{}
+	err = proto.UnmarshalText(text, &journal)
+	return &journal, err
+}
+
+// Appends to the journal, relying on repeated fields of concatenated text protos being merged
+func appendJournal(f string, entry *protos.JournalEntry) error {
+	fp, err := os.OpenFile(f, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fp.WriteString(proto.MarshalTextString(&protos.Journal{Entry: []*protos.JournalEntry{entry}})); err != nil {
+		fp.Close()
+		return err
+	}
+	return fp.Close()
+}
+
+func cloneLogEntries(logs []*protos.BuildLogEntry) []*protos.BuildLogEntry {
+	clone := make([]*protos.BuildLogEntry, len(logs))
+	for i, entry := range logs {
+		clone[i] = proto.Clone(entry).(*protos.BuildLogEntry)
+	}
+	return clone
+}
+
+// Returns the changes between two versions of the logs, matching log entries by ID
+func diffLogEntries(before []*protos.BuildLogEntry, after []*protos.BuildLogEntry) []*protos.JournalChange {
+	var changes []*protos.JournalChange
+	afterByID := map[string]*protos.BuildLogEntry{}
+	for _, entry := range after {
+		afterByID[LogEntryID(entry)] = entry
+	}
+	beforeIDs := map[string]bool{}
+	for _, b := range before {
+		beforeIDs[LogEntryID(b)] = true
+		if a, exists := afterByID[LogEntryID(b)]; !exists {
+			changes = append(changes, &protos.JournalChange{Before: b})
+		} else if !proto.Equal(a, b) {
+			changes = append(changes, &protos.JournalChange{Before: b, After: proto.Clone(a).(*protos.BuildLogEntry)})
+		}
+	}
+	for _, a := range after {
+		if !beforeIDs[LogEntryID(a)] {
+			changes = append(changes, &protos.JournalChange{After: proto.Clone(a).(*protos.BuildLogEntry)})
+		}
+	}
+	return changes
+}
+
+// Returns the changes that an update would make to the logs metadata file f, without making them
+func PreviewLogUpdate(f string, update LogUpdater) ([]*protos.JournalChange, error) {
+	logs, err := ReadLogs(f)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Could not open logs metadata from %s\n%s", f, err.Error())
+	}
+	AssignLogEntryIDs(logs.LogEntry)
+	before := cloneLogEntries(logs.LogEntry)
+	after, err := update(logs.LogEntry)
+	if err != nil {
+		return nil, err
+	}
+	AssignLogEntryIDs(after)
+	return diffLogEntries(before, after), nil
+}
+
+// Records the changes made by an update to the logs metadata file f, if any
+func journalLogUpdate(f string, before []*protos.BuildLogEntry, after []*protos.BuildLogEntry, entry *protos.JournalEntry) error {
+	entry.Change = diffLogEntries(before, after)
+	if len(entry.Change) == 0 && len(entry.Undone) == 0 {
+		return nil
+	}
+	entry.Command = journalCommand
+	entry.Timestamp = time.Now().Format(time.RFC3339)
+	if err := appendJournal(JournalPath(f), entry); err != nil {
+		return fmt.Errorf("Could not record the update in %s\n%s", JournalPath(f), err.Error())
+	}
+	recordPendingChange(filepath.Dir(filepath.Dir(f)), entry)
+	return nil
+}
+
+// Returns the ID of the log entry affected by a change
+func JournalChangeID(change *protos.JournalChange) string {
+	if change.After != nil {
+		return LogEntryID(change.After)
+	}
+	return LogEntryID(change.Before)
+}
+
+// Returns the 1-based positions of journal entries that have been reverted, mapped to the undo that reverted them
+func UndoneJournalEntries(journal *protos.Journal) map[int]int {
+	undone := map[int]int{}
+	for i, entry := range journal.Entry {
+		for _, n := range entry.Undone {
+			undone[int(n)] = i + 1
+		}
+	}
+	return undone
+}
+
+// Returns the 1-based positions of the n most recent journal entries that may be undone, most recent first.
+// Undos themselves and entries that were already undone are skipped.
+func UndoableJournalEntries(journal *protos.Journal, n int) []int {
+	undone := UndoneJournalEntries(journal)
+	var positions []int
+	for i := len(journal.Entry); i > 0 && len(positions) < n; i-- {
+		if entry := journal.Entry[i-1]; len(entry.Undone) == 0 && undone[i] == 0 && len(entry.Change) > 0 {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// Reverts the changes of a journal entry, failing if any affected log entry has since been changed
+func revertJournalEntry(logs []*protos.BuildLogEntry, entry *protos.JournalEntry) ([]*protos.BuildLogEntry, error) {
+	for i := len(entry.Change) - 1; i >= 0; i-- {
+		change := entry.Change[i]
+		if change.After != nil {
+			exists, index := FindLogEntryByID(logs, LogEntryID(change.After))
+			if !exists || !proto.Equal(logs[index], change.After) {
+				return nil, fmt.Errorf("Log entry %s has changed since '%s', revert it manually", LogEntryID(change.After), entry.Command)
+			}
+			logs = append(logs[:index], logs[index+1:]...)
+		}
+		if change.Before != nil {
+			if exists, _ := FindLogEntryByID(logs, LogEntryID(change.Before)); exists {
+				return nil, fmt.Errorf("Log entry %s has been added since '%s', revert it manually", LogEntryID(change.Before), entry.Command)
+			}
+			logs = append(logs, proto.Clone(change.Before).(*protos.BuildLogEntry))
+		}
+	}
+	return logs, nil
+}
+
+// Moves back the details files and attachments of log entries restored by reverting a journal entry, such as those
+// moved by MoveLogEntry, when they are missing but found with a log entry that was reverted
+func restoreLogEntryFiles(root string, logs []*protos.BuildLogEntry, entry *protos.JournalEntry) error {
+	referenced := map[string]bool{}
+	for _, e := range logs {
+		referenced[LogDetailsRelativePath(e.DetailsFile)] = true
+	}
+	var orphaned []string
+	attachments := map[string]string{}
+	for _, change := range entry.Change {
+		if change.After == nil {
+			continue
+		}
+		if d := LogDetailsRelativePath(change.After.DetailsFile); len(d) > 0 && !referenced[d] {
+			orphaned = append(orphaned, d)
+		}
+		for _, a := range change.After.Attachment {
+			attachments[a.Sha256] = a.File
+		}
+	}
+	for _, change := range entry.Change {
+		if change.Before == nil {
+			continue
+		}
+		if d := LogDetailsRelativePath(change.Before.DetailsFile); len(d) > 0 && len(orphaned) == 1 {
+			if exists, err := FileExists(filepath.Join(LogsDir(root), filepath.FromSlash(d))); err != nil {
+				return err
+			} else if !exists {
+				if err := moveLogsFile(root, orphaned[0], d); err != nil {
+					return err
+				}
+			}
+		}
+		for _, a := range change.Before.Attachment {
+			from, found := attachments[a.Sha256]
+			if !found || from == a.File {
+				continue
+			}
+			if exists, err := FileExists(AttachmentPath(root, a)); err != nil {
+				return err
+			} else if !exists {
+				if err := moveLogsFile(root, from, a.File); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Checks that the journal entries at the given 1-based positions exist and have not already been undone
+func CheckUndoable(journal *protos.Journal, positions []int) error {
+	undone := UndoneJournalEntries(journal)
+	for _, n := range positions {
+		if n < 1 || n > len(journal.Entry) {
+			return fmt.Errorf("Journal has no update %d", n)
+		} else if u := undone[n]; u > 0 {
+			return fmt.Errorf("Update %d was already undone by %d", n, u)
+		}
+	}
+	return nil
+}
+
+// Reverts the journal entries at the given 1-based positions, in order, recording the undo in the journal. The
+// positions are those checked by CheckUndoable against journal, and the undo fails if the journal has changed since.
+func UndoLogUpdates(root string, journal *protos.Journal, positions []int) error {
+	f := LogsPath(root)
+	undo := &protos.JournalEntry{}
+	return updateLogMetadataFile(f, func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		current, err := ReadJournal(JournalPath(f))
+		if err != nil {
+			return nil, err
+		}
+		if !proto.Equal(current, journal) {
+			return nil, errors.New("Logs were updated meanwhile, see 'history' and try again")
+		}
+		for _, n := range positions {
+			if logs, err = revertJournalEntry(logs, journal.Entry[n-1]); err != nil {
+				return nil, err
+			}
+			if err := restoreLogEntryFiles(root, logs, journal.Entry[n-1]); err != nil {
+				return nil, err
+			}
+			undo.Undone = append(undo.Undone, uint32(n))
+		}
+		return logs, nil
+	}, undo)
+}
+
+// Returns a line-by-line diff of two text representations, prefixing removed lines with "-" and added lines with "+"
+func DiffLines(a []string, b []string) []string {
+	// Longest common subsequence of lines
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var diff []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			diff = append(diff, " "+a[i])
+			i++
+			j++
+		} else if i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]) {
+			diff = append(diff, "-"+a[i])
+			i++
+		} else {
+			diff = append(diff, "+"+b[j])
+			j++
+		}
+	}
+	return diff
+}
+
+func logEntryLines(entry *protos.BuildLogEntry) []string {
+	if entry == nil {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(PrettyPrintLogEntry(entry), "\n"), "\n")
+}
+
+// Returns the diff of the text representation of a log entry before and after a change
+func DiffJournalChange(change *protos.JournalChange) []string {
+	return DiffLines(logEntryLines(change.Before), logEntryLines(change.After))
+}
+
+// Returns the names of the fields changed, e.g., title, end_time
+func ChangedFields(change *protos.JournalChange) []string {
+	var fields []string
+	seen := map[string]bool{}
+	for _, line := range DiffJournalChange(change) {
+		if line[0] == ' ' {
+			continue
+		}
+		name := strings.TrimSpace(line[1:])
+		if i := strings.IndexAny(name, ": <"); i >= 0 {
+			name = name[:i]
+		}
+		if len(name) > 0 && name != ">" && !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,156 @@
+package buildlog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+func testLogEntry(id string, title string) *protos.BuildLogEntry {
+	return &protos.BuildLogEntry{
+		Id:       id,
+		Date:     "2024-Mar-01",
+		Assembly: "fuselage",
+		Title:    title,
+		WorkPeriod: []*protos.TimePeriod{
+			{StartTime: "9:00AM", EndTime: "10:30AM", DurationMin: 90},
+		},
+	}
+}
+
+func TestDiffLogEntries(t *testing.T) {
+	before := []*protos.BuildLogEntry{
+		testLogEntry("2024-Mar-01", "Rivet longerons"),
+		testLogEntry("2024-Mar-01-2", "Prime brackets"),
+		testLogEntry("2024-Mar-01-3", "Deburr skins"),
+	}
+	after := cloneLogEntries(before)
+	after[0].Title = "Rivet upper longerons"
+	after = append(after[:1], after[2:]...)
+	after = append(after, testLogEntry("2024-Mar-01-4", "Drill gussets"))
+
+	changes := diffLogEntries(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("Got %d changes, expected 3", len(changes))
+	}
+	if c := changes[0]; c.Before == nil || c.After == nil || c.After.Title != "Rivet upper longerons" || c.Before.Title != "Rivet longerons" {
+		t.Errorf("First change is %v, expected the changed title of 2024-Mar-01", c)
+	}
+	if c := changes[1]; c.After != nil || LogEntryID(c.Before) != "2024-Mar-01-2" {
+		t.Errorf("Second change is %v, expected the removal of 2024-Mar-01-2", c)
+	}
+	if c := changes[2]; c.Before != nil || LogEntryID(c.After) != "2024-Mar-01-4" {
+		t.Errorf("Third change is %v, expected the addition of 2024-Mar-01-4", c)
+	}
+
+	// Changes are recorded as copies, unaffected by later updates
+	after[0].Title = "Changed again"
+	if changes[0].After.Title != "Rivet upper longerons" {
+		t.Errorf("Recorded change was modified along with the logs")
+	}
+	if changes := diffLogEntries(before, cloneLogEntries(before)); len(changes) != 0 {
+		t.Errorf("Got %d changes between identical logs, expected none", len(changes))
+	}
+}
+
+func TestRevertJournalEntry(t *testing.T) {
+	before := []*protos.BuildLogEntry{
+		testLogEntry("2024-Mar-01", "Rivet longerons"),
+		testLogEntry("2024-Mar-01-2", "Prime brackets"),
+	}
+	after := cloneLogEntries(before)
+	after[0].Title = "Rivet upper longerons"
+	after = append(after[:1], testLogEntry("2024-Mar-01-3", "Drill gussets"))
+	entry := &protos.JournalEntry{Command: "ccub amend", Change: diffLogEntries(before, after)}
+
+	reverted, err := revertJournalEntry(cloneLogEntries(after), entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != len(before) {
+		t.Fatalf("Got %d log entries after reverting, expected %d", len(reverted), len(before))
+	}
+	for _, b := range before {
+		exists, index := FindLogEntryByID(reverted, LogEntryID(b))
+		if !exists {
+			t.Errorf("Log entry %s was not restored", LogEntryID(b))
+		} else if !proto.Equal(reverted[index], b) {
+			t.Errorf("Log entry %s was restored as %v, expected %v", LogEntryID(b), reverted[index], b)
+		}
+	}
+}
+
+func TestRevertJournalEntryChangedSince(t *testing.T) {
+	before := []*protos.BuildLogEntry{testLogEntry("2024-Mar-01", "Rivet longerons")}
+	after := cloneLogEntries(before)
+	after[0].Title = "Rivet upper longerons"
+	entry := &protos.JournalEntry{Command: "ccub amend", Change: diffLogEntries(before, after)}
+
+	changed := cloneLogEntries(after)
+	changed[0].Title = "Rivet lower longerons"
+	if _, err := revertJournalEntry(changed, entry); err == nil {
+		t.Error("Expected an error reverting a log entry changed since")
+	}
+
+	// A removed log entry whose ID has been reused since
+	removal := &protos.JournalEntry{Command: "ccub undo", Change: diffLogEntries(before, nil)}
+	if _, err := revertJournalEntry(cloneLogEntries(before), removal); err == nil {
+		t.Error("Expected an error restoring a log entry whose ID is in use")
+	}
+}
+
+func TestUndoLogUpdatesJournalChanged(t *testing.T) {
+	root := t.TempDir()
+	f := LogsPath(root)
+	if err := os.MkdirAll(LogsDir(root), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteLogs(f, &protos.BuildLogs{LogEntry: []*protos.BuildLogEntry{testLogEntry("2024-Mar-01", "Rivet longerons")}}); err != nil {
+		t.Fatal(err)
+	}
+	retitle := func(title string) {
+		t.Helper()
+		if err := UpdateLogMetadataFile(f, func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+			logs[0].Title = title
+			return logs, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	retitle("Rivet upper longerons")
+	journal, err := ReadJournal(JournalPath(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckUndoable(journal, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The logs are updated between confirming the undo and undoing
+	retitle("Rivet lower longerons")
+	if err := UndoLogUpdates(root, journal, []int{1}); err == nil {
+		t.Error("Undo succeeded although the journal changed since, expected an error")
+	}
+
+	if journal, err = ReadJournal(JournalPath(f)); err != nil {
+		t.Fatal(err)
+	}
+	if err := UndoLogUpdates(root, journal, []int{2}); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := ReadLogs(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := logs.LogEntry[0].Title; title != "Rivet upper longerons" {
+		t.Errorf("Title after undo is %q, expected %q", title, "Rivet upper longerons")
+	}
+	if journal, err = ReadJournal(JournalPath(f)); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckUndoable(journal, []int{2}); err == nil {
+		t.Error("Update 2 can be undone again, expected an error")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+
+package buildlog
+
+import (
+	"os"
+)
+
+// Advisory locking is not supported on this platform, so concurrent updates are not serialized
+
+func lockExclusive(fp *os.File) error {
+	return nil
+}
+
+func unlock(fp *os.File) error {
+	return nil
+}
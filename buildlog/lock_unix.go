@@ -0,0 +1,21 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package buildlog
+
+import (
+	"os"
+	"syscall"
+)
+
+func lockExclusive(fp *os.File) error {
+	for {
+		err := syscall.Flock(int(fp.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func unlock(fp *os.File) error {
+	return syscall.Flock(int(fp.Fd()), syscall.LOCK_UN)
+}
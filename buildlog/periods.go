@@ -0,0 +1,224 @@
+package buildlog
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Returns the IANA name of the local time zone, e.g., America/Denver, or an empty string if it cannot be determined
+func LocalTimeZone() string {
+	if tz := os.Getenv("TZ"); len(tz) > 0 {
+		return strings.TrimPrefix(tz, ":")
+	}
+	if name := time.Local.String(); name != "Local" {
+		return name
+	}
+	if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if i := strings.Index(target, "zoneinfo/"); i >= 0 {
+			return target[i+len("zoneinfo/"):]
+		}
+	}
+	return ""
+}
+
+func timeZoneName(loc *time.Location) string {
+	if loc == time.Local {
+		return LocalTimeZone()
+	}
+	return loc.String()
+}
+
+func LoadTimeZone(name string) (*time.Location, error) {
+	if len(name) == 0 {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("Unknown time zone %s", name)
+	}
+	return loc, nil
+}
+
+func IsOpenWorkPeriod(wp *protos.TimePeriod) bool {
+	return len(wp.EndTime) == 0 && len(wp.End) == 0
+}
+
+func durationMin(d time.Duration) uint32 {
+	return uint32(math.Ceil(d.Minutes()))
+}
+
+// Constructs a work period from start to end, or an ongoing work period if end is the zero time. Times are
+// truncated to the minute, as recorded by kitchen times.
+func NewWorkPeriod(start time.Time, end time.Time) *protos.TimePeriod {
+	start = start.Truncate(time.Minute)
+	wp := &protos.TimePeriod{
+		StartTime: start.Format(time.Kitchen),
+		Start:     start.Format(time.RFC3339),
+		TimeZone:  timeZoneName(start.Location()),
+	}
+	if !end.IsZero() {
+		SetWorkPeriodEnd(wp, start, end)
+	}
+	return wp
+}
+
+func SetWorkPeriodEnd(wp *protos.TimePeriod, start time.Time, end time.Time) {
+	wp.EndTime = end.Format(time.Kitchen)
+	wp.End = end.Format(time.RFC3339)
+	wp.DurationMin = durationMin(end.Sub(start))
+}
+
+func parseWorkPeriodTimestamp(wp *protos.TimePeriod, ts string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid work period timestamp: %s", ts)
+	}
+	if len(wp.TimeZone) > 0 {
+		if loc, err := LoadTimeZone(wp.TimeZone); err == nil {
+			return t.In(loc), nil
+		}
+	}
+	return t, nil
+}
+
+// Returns the time at which a work period began, falling back to the kitchen time on the date of the log entry
+// in the local time zone for work periods logged prior to the addition of timestamps
+func WorkPeriodStart(entry *protos.BuildLogEntry, wp *protos.TimePeriod) (time.Time, error) {
+	if len(wp.Start) > 0 {
+		return parseWorkPeriodTimestamp(wp, wp.Start)
+	}
+	date, err := ParseDateOfLog(entry)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ParseKitchenTime(date.Year(), date.Month(), date.Day(), wp.StartTime, time.Local)
+}
+
+// Returns the time at which a work period ended; an end kitchen time that is not after the start kitchen time
+// is taken to be on the following day
+func WorkPeriodEnd(entry *protos.BuildLogEntry, wp *protos.TimePeriod) (time.Time, error) {
+	if len(wp.End) > 0 {
+		return parseWorkPeriodTimestamp(wp, wp.End)
+	}
+	if len(wp.EndTime) == 0 {
+		return time.Time{}, fmt.Errorf("Work period starting at %s on %s is ongoing", wp.StartTime, entry.Date)
+	}
+	start, err := WorkPeriodStart(entry, wp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	end, err := ParseKitchenTime(start.Year(), start.Month(), start.Day(), wp.EndTime, start.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end, nil
+}
+
+// Fills in the RFC 3339 timestamps and time zone of work periods recorded only as kitchen times, interpreting
+// kitchen times in the specified time zone, and recomputes their durations from the timestamps such that work periods
+// spanning a daylight saving time change have the time actually elapsed. Returns the number of work periods updated
+// and the number of those whose duration changed.
+func BackfillWorkPeriodTimestamps(logs []*protos.BuildLogEntry, loc *time.Location) (int, int, error) {
+	zone := timeZoneName(loc)
+	count := 0
+	corrected := 0
+	for _, entry := range logs {
+		date, err := ParseDateOfLog(entry)
+		if err != nil {
+			return count, corrected, err
+		}
+		for _, wp := range entry.WorkPeriod {
+			if len(wp.Start) > 0 {
+				continue
+			}
+			start, err := ParseKitchenTime(date.Year(), date.Month(), date.Day(), wp.StartTime, loc)
+			if err != nil {
+				return count, corrected, fmt.Errorf("Log entry %s: %s", entry.Date, err.Error())
+			}
+			wp.Start = start.Format(time.RFC3339)
+			wp.TimeZone = zone
+			if len(wp.EndTime) > 0 {
+				end, err := WorkPeriodEnd(entry, wp)
+				if err != nil {
+					return count, corrected, fmt.Errorf("Log entry %s: %s", entry.Date, err.Error())
+				}
+				wp.End = end.Format(time.RFC3339)
+				if d := durationMin(end.Sub(start)); d != wp.DurationMin {
+					wp.DurationMin = d
+					corrected++
+				}
+			}
+			count++
+		}
+	}
+	return count, corrected, nil
+}
+
+func startOfNextDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+}
+
+// Stops the open work period at logs[entryIndex].WorkPeriod[periodIndex] at the specified end time.
+//
+// A work period that crosses midnight is split at each midnight: the original period ends at 12:00AM and
+// the remainder is recorded on the log entries for the same assembly on the following day(s), which are created as
+// needed by inheriting the assembly, subassemblies, title and tags of the entry on which the period was started, each
+// with a details file of its own. The remainder is attributed to the same builder and helpers.
+func CloseWorkPeriod(logs []*protos.BuildLogEntry, entryIndex int, periodIndex int, end time.Time) ([]*protos.BuildLogEntry, uint32, error) {
+	entry := logs[entryIndex]
+	pw := entry.WorkPeriod[periodIndex]
+	start, err := WorkPeriodStart(entry, pw)
+	if err != nil {
+		return nil, 0, err
+	}
+	end = end.In(start.Location()).Truncate(time.Minute)
+	if end.Before(start) {
+		return nil, 0, fmt.Errorf("Work period cannot end at %s, before it started at %s on %s", end.Format(time.Kitchen), pw.StartTime, entry.Date)
+	}
+
+	total := uint32(0)
+	segEnd := end
+	if midnight := startOfNextDay(start); segEnd.After(midnight) {
+		segEnd = midnight
+	}
+	if len(pw.Start) == 0 {
+		pw.Start = start.Format(time.RFC3339)
+		pw.TimeZone = timeZoneName(start.Location())
+	}
+	SetWorkPeriodEnd(pw, start, segEnd)
+	total += pw.DurationMin
+
+	for segStart := segEnd; segStart.Before(end); segStart = segEnd {
+		segEnd = end
+		if midnight := startOfNextDay(segStart); segEnd.After(midnight) {
+			segEnd = midnight
+		}
+		period := NewWorkPeriod(segStart, segEnd)
+		period.TimeZone = pw.TimeZone
+		period.Builder = pw.Builder
+		period.Helper = pw.Helper
+		total += period.DurationMin
+		if exists, index := FindLogEntry(logs, segStart, entry.Assembly); exists {
+			logs[index].WorkPeriod = append(logs[index].WorkPeriod, period)
+		} else {
+			logs = AppendLogEntry(logs, &protos.BuildLogEntry{
+				Assembly:    entry.Assembly,
+				Subassembly: entry.Subassembly,
+				Date:        FormatDateForLog(segStart),
+				Title:       entry.Title,
+				WorkPeriod:  []*protos.TimePeriod{period},
+				Tags:        entry.Tags,
+			})
+		}
+	}
+	return logs, total, nil
+}
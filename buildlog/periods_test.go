@@ -0,0 +1,112 @@
+package buildlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+func TestCloseWorkPeriodSplitsAtMidnight(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 22, 30, 0, 0, time.UTC)
+	end := time.Date(2024, time.March, 3, 1, 15, 0, 0, time.UTC)
+	wp := NewWorkPeriod(start, time.Time{})
+	wp.Builder = "Craig"
+	wp.Helper = []string{"Sam"}
+	logs := AppendLogEntry(nil, &protos.BuildLogEntry{
+		Assembly:   "fuselage",
+		Date:       FormatDateForLog(start),
+		Title:      "Rivet longerons",
+		Tags:       []string{"riveting"},
+		WorkPeriod: []*protos.TimePeriod{wp},
+	})
+
+	logs, total, err := CloseWorkPeriod(logs, 0, 0, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 90+1440+75 {
+		t.Errorf("Total is %d minutes, expected %d", total, 90+1440+75)
+	}
+	if len(logs) != 3 {
+		t.Fatalf("Got %d log entries, expected 3", len(logs))
+	}
+	expected := []struct {
+		date     string
+		start    string
+		end      string
+		duration uint32
+	}{
+		{"2024-Mar-01", "10:30PM", "12:00AM", 90},
+		{"2024-Mar-02", "12:00AM", "12:00AM", 1440},
+		{"2024-Mar-03", "12:00AM", "1:15AM", 75},
+	}
+	for i, e := range expected {
+		entry := logs[i]
+		if entry.Date != e.date || entry.Assembly != "fuselage" || entry.Title != "Rivet longerons" {
+			t.Errorf("Log entry %d is %s %s %q, expected %s fuselage %q", i, entry.Date, entry.Assembly, entry.Title, e.date, "Rivet longerons")
+		}
+		// Each log entry has its own details file
+		date, _ := ParseDateOfLog(entry)
+		if expected := RelativeLogDetailsFile(date, entry.Id); entry.DetailsFile != expected {
+			t.Errorf("Log entry %d has details file %s, expected %s", i, entry.DetailsFile, expected)
+		}
+		if len(entry.WorkPeriod) != 1 {
+			t.Fatalf("Log entry %d has %d work periods, expected 1", i, len(entry.WorkPeriod))
+		}
+		p := entry.WorkPeriod[0]
+		if p.StartTime != e.start || p.EndTime != e.end || p.DurationMin != e.duration {
+			t.Errorf("Log entry %d has work period %s-%s (%d min), expected %s-%s (%d min)", i, p.StartTime, p.EndTime, p.DurationMin, e.start, e.end, e.duration)
+		}
+		if p.Builder != "Craig" || len(p.Helper) != 1 || p.Helper[0] != "Sam" {
+			t.Errorf("Log entry %d has work period by %s with %v, expected Craig with [Sam]", i, p.Builder, p.Helper)
+		}
+		if p.TimeZone != "UTC" {
+			t.Errorf("Log entry %d has work period in time zone %s, expected UTC", i, p.TimeZone)
+		}
+	}
+}
+
+func TestCloseWorkPeriodAppendsToExistingEntry(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 23, 0, 0, 0, time.UTC)
+	next := time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC)
+	logs := AppendLogEntry(nil, &protos.BuildLogEntry{
+		Assembly:   "fuselage",
+		Date:       FormatDateForLog(start),
+		WorkPeriod: []*protos.TimePeriod{NewWorkPeriod(start, time.Time{})},
+	})
+	logs = AppendLogEntry(logs, &protos.BuildLogEntry{
+		Assembly:   "fuselage",
+		Date:       FormatDateForLog(next),
+		WorkPeriod: []*protos.TimePeriod{NewWorkPeriod(next, next.Add(time.Hour))},
+	})
+
+	logs, total, err := CloseWorkPeriod(logs, 0, 0, start.Add(90*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 90 {
+		t.Errorf("Total is %d minutes, expected 90", total)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Got %d log entries, expected 2", len(logs))
+	}
+	if n := len(logs[1].WorkPeriod); n != 2 {
+		t.Fatalf("Log entry on the following day has %d work periods, expected 2", n)
+	}
+	if p := logs[1].WorkPeriod[1]; p.StartTime != "12:00AM" || p.EndTime != "12:30AM" || p.DurationMin != 30 {
+		t.Errorf("Remainder is %s-%s (%d min), expected 12:00AM-12:30AM (30 min)", p.StartTime, p.EndTime, p.DurationMin)
+	}
+}
+
+func TestCloseWorkPeriodBeforeStart(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	logs := AppendLogEntry(nil, &protos.BuildLogEntry{
+		Assembly:   "fuselage",
+		Date:       FormatDateForLog(start),
+		WorkPeriod: []*protos.TimePeriod{NewWorkPeriod(start, time.Time{})},
+	})
+	if _, _, err := CloseWorkPeriod(logs, 0, 0, start.Add(-time.Hour)); err == nil {
+		t.Error("Expected an error for a work period ending before it started")
+	}
+}
@@ -0,0 +1,77 @@
+package buildlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+const ProjectEnvVar = "CCUB_HOME"
+
+// Whether dir is the root of a project, marked by either a project config or a log file
+func IsProjectRoot(dir string) (bool, error) {
+	for _, f := range []string{ConfigPath(dir), LogsPath(dir)} {
+		if exists, err := FileExists(f); err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// Locates the root directory of the project. An explicitly specified directory takes precedence, followed by the
+// CCUB_HOME environment variable, followed by the nearest of the current working directory and its parents
+// that is a project root.
+func FindProjectRoot(explicit string) (string, error) {
+	if len(explicit) == 0 {
+		explicit = os.Getenv(ProjectEnvVar)
+	}
+	if len(explicit) > 0 {
+		if isRoot, err := IsProjectRoot(explicit); err != nil {
+			return "", err
+		} else if !isRoot {
+			return "", fmt.Errorf("%s is not a ccub project, run 'init' to create one", explicit)
+		}
+		return explicit, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	// Walk up using relative paths so that file paths shown to the user remain short
+	rel := "."
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		if isRoot, err := IsProjectRoot(dir); err != nil {
+			return "", err
+		} else if isRoot {
+			return rel, nil
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+		rel = filepath.Join(rel, "..")
+	}
+	return "", errors.New("Not a ccub project (or any parent directory), run 'init' to create one or set " + ProjectEnvVar)
+}
+
+// Creates the project config and an empty log in dir
+func InitProject(dir string) error {
+	if isRoot, err := IsProjectRoot(dir); err != nil {
+		return err
+	} else if isRoot {
+		return fmt.Errorf("%s is already a ccub project", dir)
+	}
+	if err := EnsureDirExists(dir); err != nil {
+		return err
+	}
+	if err := WriteProjectConfig(dir, DefaultProjectConfig()); err != nil {
+		return err
+	}
+	if err := WriteLogs(LogsPath(dir), &protos.BuildLogs{}); err != nil {
+		return err
+	}
+	_, err := EnsureLogsGitignore(dir)
+	return err
+}
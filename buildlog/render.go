@@ -0,0 +1,237 @@
+package buildlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Functions available to render templates in addition to TemplateFuncs, for selecting and ordering log entries,
+// e.g., {{range .Entries | where "assembly" "left wing" | sortBy "minutes" | reverse | limit 5}}
+var renderFuncs = template.FuncMap{
+	"where":   whereEntries,
+	"sortBy":  SortRenderEntries,
+	"reverse": ReverseRenderEntries,
+	"limit":   LimitRenderEntries,
+	"minutes": totalEntryMinutes,
+}
+
+type RenderEntry struct {
+	*protos.BuildLogEntry
+	Details string
+	// Date of the log entry
+	Time    time.Time
+	Minutes int
+	// Running totals of work time within the assembly and across the entire build, including this entry
+	AssemblyCumulativeMinutes int
+	CumulativeMinutes         int
+}
+
+// Log entries sharing an assembly, subassembly, tag, month or builder. The minutes of a builder's group count only
+// their own work periods.
+type RenderGroup struct {
+	Name    string
+	Minutes int
+	Entries []*RenderEntry
+}
+
+// Data model passed to render templates, executed once for the entire log
+type RenderData struct {
+	Generated    time.Time
+	FirstDate    string
+	LastDate     string
+	TotalMinutes int
+	// Log entries in chronological order
+	Entries []*RenderEntry
+	// Groups in order of their first log entry
+	Assemblies []*RenderGroup
+	Months     []*RenderGroup
+	// Groups in alphabetical order
+	Subassemblies []*RenderGroup
+	Tags          []*RenderGroup
+	Builders      []*RenderGroup
+}
+
+func addToRenderGroup(groups map[string]*RenderGroup, list *[]*RenderGroup, name string, entry *RenderEntry, minutes int) *RenderGroup {
+	group, exists := groups[name]
+	if !exists {
+		group = &RenderGroup{Name: name}
+		groups[name] = group
+		*list = append(*list, group)
+	}
+	group.Minutes += minutes
+	group.Entries = append(group.Entries, entry)
+	return group
+}
+
+func sortRenderGroups(groups []*RenderGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+}
+
+func NewRenderData(root string, logs []*protos.BuildLogEntry) (*RenderData, error) {
+	data := &RenderData{Generated: time.Now()}
+	assemblies := map[string]*RenderGroup{}
+	months := map[string]*RenderGroup{}
+	subassemblies := map[string]*RenderGroup{}
+	tags := map[string]*RenderGroup{}
+	builders := map[string]*RenderGroup{}
+	for _, log := range logs {
+		date, err := ParseDateOfLog(log)
+		if err != nil {
+			return nil, err
+		}
+		details, err := ReadLogDetails(root, log)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		entry := &RenderEntry{
+			BuildLogEntry: log,
+			Details:       details,
+			Time:          date,
+			Minutes:       LogEntryMinutes(log),
+		}
+		data.TotalMinutes += entry.Minutes
+		entry.CumulativeMinutes = data.TotalMinutes
+		entry.AssemblyCumulativeMinutes = addToRenderGroup(assemblies, &data.Assemblies, log.Assembly, entry, entry.Minutes).Minutes
+		addToRenderGroup(months, &data.Months, date.Format(MonthLayout), entry, entry.Minutes)
+		for _, s := range log.Subassembly {
+			addToRenderGroup(subassemblies, &data.Subassemblies, s, entry, entry.Minutes)
+		}
+		for _, t := range log.Tags {
+			addToRenderGroup(tags, &data.Tags, t, entry, entry.Minutes)
+		}
+		for b, m := range LogEntryBuilderMinutes(log) {
+			addToRenderGroup(builders, &data.Builders, b, entry, m)
+		}
+		if len(data.FirstDate) == 0 {
+			data.FirstDate = log.Date
+		}
+		data.LastDate = log.Date
+		data.Entries = append(data.Entries, entry)
+	}
+	sortRenderGroups(data.Subassemblies)
+	sortRenderGroups(data.Tags)
+	sortRenderGroups(data.Builders)
+	return data, nil
+}
+
+func NewRenderTemplate(name string) *template.Template {
+	return NewTemplate(name).Funcs(renderFuncs)
+}
+
+// Parses a set of template files, each of which may be a glob pattern, such that templates may include partials
+// and layouts defined in any of the files. The set executes the first file by default.
+func LoadRenderTemplates(patterns []string) (*template.Template, error) {
+	var files []string
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("No template files match %s", p)
+		}
+		files = append(files, matches...)
+	}
+	var tmpl *template.Template
+	for _, f := range files {
+		text, err := ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		t := tmpl
+		if t == nil {
+			tmpl = NewRenderTemplate(filepath.Base(f))
+			t = tmpl
+		} else {
+			t = tmpl.New(filepath.Base(f))
+		}
+		if _, err := t.Parse(text); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+func whereEntries(key string, value string, entries []*RenderEntry) ([]*RenderEntry, error) {
+	var matches []*RenderEntry
+	for _, e := range entries {
+		var match bool
+		switch key {
+		case "assembly":
+			match = e.Assembly == value
+		case "subassembly":
+			match = containsString(e.Subassembly, value)
+		case "tag":
+			match = containsString(e.Tags, value)
+		case "month":
+			match = e.Time.Format(MonthLayout) == value
+		case "year":
+			match = e.Time.Format("2006") == value
+		case "builder":
+			_, match = LogEntryBuilderMinutes(e.BuildLogEntry)[value]
+		default:
+			return nil, fmt.Errorf("Cannot filter log entries by %q, expected one of: assembly, subassembly, tag, month, year, builder", key)
+		}
+		if match {
+			matches = append(matches, e)
+		}
+	}
+	return matches, nil
+}
+
+func ValidRenderSortKeys() []string {
+	return []string{"date", "minutes", "title", "assembly"}
+}
+
+func SortRenderEntries(key string, entries []*RenderEntry) ([]*RenderEntry, error) {
+	var less func(a *RenderEntry, b *RenderEntry) bool
+	switch key {
+	case "date":
+		less = func(a *RenderEntry, b *RenderEntry) bool { return a.Time.Before(b.Time) }
+	case "minutes":
+		less = func(a *RenderEntry, b *RenderEntry) bool { return a.Minutes < b.Minutes }
+	case "title":
+		less = func(a *RenderEntry, b *RenderEntry) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case "assembly":
+		less = func(a *RenderEntry, b *RenderEntry) bool { return a.Assembly < b.Assembly }
+	default:
+		return nil, fmt.Errorf("Cannot sort log entries by %q, expected one of: %s", key, strings.Join(ValidRenderSortKeys(), ", "))
+	}
+	sorted := append([]*RenderEntry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted, nil
+}
+
+func ReverseRenderEntries(entries []*RenderEntry) []*RenderEntry {
+	reversed := make([]*RenderEntry, len(entries))
+	for i, e := range entries {
+		reversed[len(entries)-1-i] = e
+	}
+	return reversed
+}
+
+func LimitRenderEntries(n int, entries []*RenderEntry) []*RenderEntry {
+	if n < len(entries) {
+		return entries[:n]
+	}
+	return entries
+}
+
+func totalEntryMinutes(entries []*RenderEntry) int {
+	total := 0
+	for _, e := range entries {
+		total += e.Minutes
+	}
+	return total
+}
@@ -0,0 +1,150 @@
+package buildlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+const (
+	noSubassemblyKey = "(none)"
+	untaggedKey      = "(untagged)"
+)
+
+var validReportGroupings = []string{
+	"assembly",
+	"subassembly",
+	"tag",
+	"builder",
+	"week",
+	"month",
+	"year",
+}
+
+type HoursTotal struct {
+	Group   string `json:"group"`
+	Entries int    `json:"entries"`
+	Minutes int    `json:"minutes"`
+	// Work time of the group by builder, keyed by UnattributedKey for work not attributed to anyone
+	BuilderMinutes map[string]int `json:"builder_minutes,omitempty"`
+}
+
+func (t HoursTotal) Hours() float64 {
+	return float64(t.Minutes) / 60
+}
+
+func ParseReportGroupingArg(arg string) (string, error) {
+	if !containsString(validReportGroupings, arg) {
+		return "", fmt.Errorf("Grouping must be one of:\n  %s", strings.Join(validReportGroupings, "\n  "))
+	}
+	return arg, nil
+}
+
+func ValidReportGroupings() []string {
+	return validReportGroupings
+}
+
+func LogEntryMinutes(entry *protos.BuildLogEntry) int {
+	total := 0
+	for _, wp := range entry.WorkPeriod {
+		total += int(wp.DurationMin)
+	}
+	return total
+}
+
+// Returns the keys of all groups to which a log entry belongs; an entry with several subassemblies or tags counts
+// toward each of them, whereas the work time of an entry is divided among the builders of its work periods
+func reportGroupKeys(entry *protos.BuildLogEntry, date time.Time, grouping string) []string {
+	switch grouping {
+	case "assembly":
+		return []string{entry.Assembly}
+	case "subassembly":
+		if len(entry.Subassembly) == 0 {
+			return []string{noSubassemblyKey}
+		}
+		return entry.Subassembly
+	case "tag":
+		if len(entry.Tags) == 0 {
+			return []string{untaggedKey}
+		}
+		return entry.Tags
+	case "builder":
+		var builders []string
+		for b := range LogEntryBuilderMinutes(entry) {
+			builders = append(builders, b)
+		}
+		if len(builders) == 0 {
+			return []string{UnattributedKey}
+		}
+		sort.Strings(builders)
+		return builders
+	case "week":
+		year, week := date.ISOWeek()
+		return []string{fmt.Sprintf("%d-W%02d", year, week)}
+	case "month":
+		return []string{date.Format(MonthLayout)}
+	case "year":
+		return []string{date.Format("2006")}
+	}
+	return nil
+}
+
+func isChronologicalGrouping(grouping string) bool {
+	return grouping == "week" || grouping == "month" || grouping == "year"
+}
+
+// Totals the work time of log entries by group. Chronological groupings are ordered by date, all others
+// alphabetically.
+func HoursReport(logs []*protos.BuildLogEntry, grouping string) ([]HoursTotal, error) {
+	var totals []HoursTotal
+	index := map[string]int{}
+	for _, entry := range logs {
+		date, err := ParseDateOfLog(entry)
+		if err != nil {
+			return nil, err
+		}
+		builders := LogEntryBuilderMinutes(entry)
+		for _, key := range reportGroupKeys(entry, date, grouping) {
+			i, exists := index[key]
+			if !exists {
+				i = len(totals)
+				index[key] = i
+				totals = append(totals, HoursTotal{Group: key})
+			}
+			totals[i].Entries++
+			if grouping == "builder" {
+				totals[i].Minutes += builders[key]
+				continue
+			}
+			totals[i].Minutes += LogEntryMinutes(entry)
+			if totals[i].BuilderMinutes == nil {
+				totals[i].BuilderMinutes = map[string]int{}
+			}
+			for b, m := range builders {
+				totals[i].BuilderMinutes[b] += m
+			}
+		}
+	}
+	if !isChronologicalGrouping(grouping) {
+		sort.SliceStable(totals, func(i, j int) bool {
+			return totals[i].Group < totals[j].Group
+		})
+	}
+	return totals, nil
+}
+
+// Totals the work time of log entries as a whole, counting each log entry once however many groups it belongs to
+func HoursReportTotal(logs []*protos.BuildLogEntry) HoursTotal {
+	total := HoursTotal{Group: "Total", BuilderMinutes: map[string]int{}}
+	for _, entry := range logs {
+		total.Entries++
+		total.Minutes += LogEntryMinutes(entry)
+		for b, m := range LogEntryBuilderMinutes(entry) {
+			total.BuilderMinutes[b] += m
+		}
+	}
+	return total
+}
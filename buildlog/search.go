@@ -0,0 +1,268 @@
+package buildlog
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+const (
+	titleWeight   = 3
+	metaWeight    = 2
+	detailsWeight = 1
+	snippetRadius = 60
+)
+
+// A sequence of words that must appear consecutively; a trailing * on the final word matches any word with that
+// prefix
+type searchPhrase []string
+
+// Matches if any of its phrases match, or if none match when negated
+type searchClause struct {
+	phrases []searchPhrase
+	negate  bool
+}
+
+// All clauses must match
+type SearchQuery struct {
+	clauses []searchClause
+}
+
+type SearchResult struct {
+	Index   int
+	Score   float64
+	Snippet string
+}
+
+// Splits a query into words and quoted phrases
+func lexQuery(query string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	quoted := false
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range query {
+		switch {
+		case r == '"':
+			cur.WriteRune(r)
+			if quoted {
+				flush()
+			}
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errors.New("Unterminated quoted phrase in search query")
+	}
+	flush()
+	return tokens, nil
+}
+
+func parsePhrase(text string) searchPhrase {
+	prefix := strings.HasSuffix(text, "*")
+	words := Tokenize(text)
+	if prefix && len(words) > 0 {
+		words[len(words)-1] += "*"
+	}
+	return words
+}
+
+// Parses a search query. Words and "quoted phrases" must all match, unless joined by OR, in which case either
+// may match. Words and phrases preceded by - or NOT must not match.
+func ParseSearchQuery(query string) (*SearchQuery, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	q := &SearchQuery{}
+	negate := false
+	or := false
+	for _, tok := range tokens {
+		switch tok {
+		case "OR":
+			if len(q.clauses) == 0 || q.clauses[len(q.clauses)-1].negate {
+				return nil, errors.New("OR must follow a word or phrase")
+			}
+			or = true
+			continue
+		case "AND":
+			continue
+		case "NOT":
+			negate = true
+			continue
+		}
+		if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+			negate = true
+			tok = tok[1:]
+		}
+		phrase := parsePhrase(tok)
+		if len(phrase) == 0 {
+			negate, or = false, false
+			continue
+		}
+		if or && !negate {
+			last := &q.clauses[len(q.clauses)-1]
+			last.phrases = append(last.phrases, phrase)
+		} else {
+			q.clauses = append(q.clauses, searchClause{phrases: []searchPhrase{phrase}, negate: negate})
+		}
+		negate, or = false, false
+	}
+	if or {
+		return nil, errors.New("OR must precede a word or phrase")
+	}
+	positive := false
+	for _, c := range q.clauses {
+		positive = positive || !c.negate
+	}
+	if !positive {
+		return nil, errors.New("Search query must contain at least one word or phrase to match")
+	}
+	return q, nil
+}
+
+func wordMatches(pattern string, word string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(word, pattern[:len(pattern)-1])
+	}
+	return pattern == word
+}
+
+// Counts the occurrences of phrase in tokens
+func countPhrase(phrase searchPhrase, tokens []string) int {
+	count := 0
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, p := range phrase {
+			if !wordMatches(p, tokens[i+j]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
+	}
+	return count
+}
+
+type searchDocument struct {
+	title   []string
+	meta    []string
+	details []string
+}
+
+func (doc *searchDocument) count(phrase searchPhrase) float64 {
+	return float64(titleWeight*countPhrase(phrase, doc.title) +
+		metaWeight*countPhrase(phrase, doc.meta) +
+		detailsWeight*countPhrase(phrase, doc.details))
+}
+
+// Returns the matching log entries in order of decreasing relevance, scored by the weighted frequency of
+// each phrase in the title, tags and assembly, and details, scaled by the rarity of the phrase across all log
+// entries
+func Search(logs []*protos.BuildLogEntry, details map[string]*protos.SearchIndexDocument, query *SearchQuery) []SearchResult {
+	docs := make([]searchDocument, len(logs))
+	for i, entry := range logs {
+		docs[i] = searchDocument{
+			title: Tokenize(entry.Title),
+			meta:  Tokenize(strings.Join(append(append([]string{entry.Assembly}, entry.Subassembly...), entry.Tags...), " ")),
+		}
+		if doc, exists := details[entry.DetailsFile]; exists {
+			docs[i].details = doc.Token
+		}
+	}
+
+	// Inverse document frequency of each phrase
+	idf := map[string]float64{}
+	for _, c := range query.clauses {
+		for _, p := range c.phrases {
+			key := strings.Join(p, " ")
+			df := 0
+			for i := range docs {
+				if docs[i].count(p) > 0 {
+					df++
+				}
+			}
+			idf[key] = math.Log(1 + float64(len(docs))/float64(1+df))
+		}
+	}
+
+	var results []SearchResult
+	for i := range docs {
+		score := 0.0
+		match := true
+		for _, c := range query.clauses {
+			clauseScore := 0.0
+			for _, p := range c.phrases {
+				if n := docs[i].count(p); n > 0 {
+					clauseScore += (1 + math.Log(n)) * idf[strings.Join(p, " ")]
+				}
+			}
+			if (clauseScore > 0) == c.negate {
+				match = false
+				break
+			}
+			score += clauseScore
+		}
+		if match {
+			results = append(results, SearchResult{Index: i, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// Returns a single line excerpt of text around the first occurrence of any word matched by the query
+func (q *SearchQuery) Snippet(text string) string {
+	lower := strings.ToLower(text)
+	pos := -1
+	for _, c := range q.clauses {
+		if c.negate {
+			continue
+		}
+		for _, p := range c.phrases {
+			word := strings.TrimSuffix(p[0], "*")
+			if i := strings.Index(lower, word); i >= 0 && (pos < 0 || i < pos) {
+				pos = i
+			}
+		}
+	}
+	if pos < 0 {
+		return ""
+	}
+	if pos > len(text) {
+		pos = len(text)
+	}
+	start, end := pos-snippetRadius, pos+snippetRadius
+	prefix, suffix := "...", "..."
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	}
+	// Avoid splitting multi-byte characters
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	return prefix + strings.Join(strings.Fields(text[start:end]), " ") + suffix
+}
@@ -0,0 +1,110 @@
+package buildlog
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		clauses []searchClause
+	}{
+		{"rivet", []searchClause{
+			{phrases: []searchPhrase{{"rivet"}}},
+		}},
+		{"Rivet AND fitting*", []searchClause{
+			{phrases: []searchPhrase{{"rivet"}}},
+			{phrases: []searchPhrase{{"fitting*"}}},
+		}},
+		{`"lift strut" fitting -rivet`, []searchClause{
+			{phrases: []searchPhrase{{"lift", "strut"}}},
+			{phrases: []searchPhrase{{"fitting"}}},
+			{phrases: []searchPhrase{{"rivet"}}, negate: true},
+		}},
+		{`wing OR "lift strut" NOT tail`, []searchClause{
+			{phrases: []searchPhrase{{"wing"}, {"lift", "strut"}}},
+			{phrases: []searchPhrase{{"tail"}}, negate: true},
+		}},
+		{"fitting -- ,", []searchClause{
+			{phrases: []searchPhrase{{"fitting"}}},
+		}},
+	}
+	for _, test := range tests {
+		q, err := ParseSearchQuery(test.query)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q) failed: %s", test.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.clauses, test.clauses) {
+			t.Errorf("ParseSearchQuery(%q) = %v, expected %v", test.query, q.clauses, test.clauses)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		`"lift strut`,
+		"OR rivet",
+		"rivet OR",
+		"-rivet OR fitting",
+		"-rivet",
+		"NOT rivet",
+		"",
+	} {
+		if _, err := ParseSearchQuery(query); err == nil {
+			t.Errorf("ParseSearchQuery(%q) succeeded, expected an error", query)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	logs := []*protos.BuildLogEntry{
+		{Assembly: "left wing", Title: "Install lift strut fittings"},
+		{Assembly: "left wing", Title: "Rivet lift strut fitting brackets", Tags: []string{"riveting"}},
+		{Assembly: "fuselage", Title: "Rivet longerons", Tags: []string{"riveting"}},
+		{Assembly: "right wing", Title: "Strut lift check", DetailsFile: "2024-Mar/2024-Mar-01.md"},
+	}
+	details := map[string]*protos.SearchIndexDocument{
+		"2024-Mar/2024-Mar-01.md": {Token: Tokenize("Checked the lift strut fitting alignment")},
+	}
+	tests := []struct {
+		query   string
+		indices []int
+	}{
+		{`"lift strut"`, []int{0, 1, 3}},
+		{`"lift strut" -rivet`, []int{0, 3}},
+		{"fitting*", []int{0, 1, 3}},
+		{"riveting", []int{1, 2}},
+		{"longerons OR brackets", []int{1, 2}},
+		{"fuselage rivet", []int{2}},
+		{"tail", nil},
+	}
+	for _, test := range tests {
+		q, err := ParseSearchQuery(test.query)
+		if err != nil {
+			t.Fatalf("ParseSearchQuery(%q) failed: %s", test.query, err)
+		}
+		var indices []int
+		for _, r := range Search(logs, details, q) {
+			indices = append(indices, r.Index)
+		}
+		// Compare regardless of ranking
+		matched := map[int]bool{}
+		for _, i := range indices {
+			matched[i] = true
+		}
+		if len(indices) != len(test.indices) {
+			t.Errorf("Search(%q) matched %v, expected %v", test.query, indices, test.indices)
+			continue
+		}
+		for _, i := range test.indices {
+			if !matched[i] {
+				t.Errorf("Search(%q) matched %v, expected %v", test.query, indices, test.indices)
+				break
+			}
+		}
+	}
+}
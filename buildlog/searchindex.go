@@ -0,0 +1,93 @@
+package buildlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+const searchIndexFile = ".search.index"
+
+func SearchIndexPath(root string) string {
+	return filepath.Join(LogsDir(root), searchIndexFile)
+}
+
+// Splits text into lowercase words
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func readSearchIndex(f string) map[string]*protos.SearchIndexDocument {
+	docs := map[string]*protos.SearchIndexDocument{}
+	data, err := os.ReadFile(f)
+	if err != nil {
+		return docs
+	}
+	index := &protos.SearchIndex{}
+	// A corrupt index is simply rebuilt
+	if err := proto.Unmarshal(data, index); err != nil {
+		return docs
+	}
+	for _, doc := range index.Document {
+		docs[doc.DetailsFile] = doc
+	}
+	return docs
+}
+
+// Returns the indexed tokens of the details files of all log entries, keyed by BuildLogEntry.DetailsFile. Only
+// details files that changed since they were last indexed are read, after which the index is saved if updated.
+func UpdateSearchIndex(root string, logs []*protos.BuildLogEntry) (map[string]*protos.SearchIndexDocument, error) {
+	f := SearchIndexPath(root)
+	cached := readSearchIndex(f)
+	docs := map[string]*protos.SearchIndexDocument{}
+	changed := false
+	for _, entry := range logs {
+		if _, exists := docs[entry.DetailsFile]; exists || len(entry.DetailsFile) == 0 {
+			continue
+		}
+		fi, err := os.Stat(LogDetailsPath(root, entry))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		doc, exists := cached[entry.DetailsFile]
+		if !exists || doc.ModTimeUnixNano != fi.ModTime().UnixNano() || doc.Size != fi.Size() {
+			details, err := ReadFile(LogDetailsPath(root, entry))
+			if err != nil {
+				return nil, err
+			}
+			doc = &protos.SearchIndexDocument{
+				DetailsFile:     entry.DetailsFile,
+				ModTimeUnixNano: fi.ModTime().UnixNano(),
+				Size:            fi.Size(),
+				Token:           Tokenize(details),
+			}
+			changed = true
+		}
+		docs[entry.DetailsFile] = doc
+	}
+	if changed || len(docs) != len(cached) {
+		index := &protos.SearchIndex{}
+		saved := map[string]bool{}
+		for _, entry := range logs {
+			if doc, exists := docs[entry.DetailsFile]; exists && !saved[entry.DetailsFile] {
+				index.Document = append(index.Document, doc)
+				saved[entry.DetailsFile] = true
+			}
+		}
+		data, err := proto.Marshal(index)
+		if err != nil {
+			return nil, err
+		}
+		// The index is only a cache, so failing to save it is not an error
+		WriteFileAtomic(f, data, 0644)
+	}
+	return docs, nil
+}
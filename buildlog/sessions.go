@@ -0,0 +1,165 @@
+package buildlog
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Locates a work period within the logs, along with its start and end; End is zero if the work period is open
+type WorkPeriodRef struct {
+	EntryIndex  int
+	PeriodIndex int
+	Start       time.Time
+	End         time.Time
+}
+
+func (r *WorkPeriodRef) Period(logs []*protos.BuildLogEntry) *protos.TimePeriod {
+	return logs[r.EntryIndex].WorkPeriod[r.PeriodIndex]
+}
+
+// Minutes worked in the work period, counting an open work period as ending at now
+func (r *WorkPeriodRef) Minutes(now time.Time) int {
+	end := r.End
+	if end.IsZero() {
+		end = now
+	}
+	if end.Before(r.Start) {
+		return 0
+	}
+	return int(end.Sub(r.Start).Minutes())
+}
+
+// Returns all work periods in order of their start, omitting any with invalid times
+func SortedWorkPeriods(logs []*protos.BuildLogEntry) []WorkPeriodRef {
+	var refs []WorkPeriodRef
+	for i, entry := range logs {
+		for j, wp := range entry.WorkPeriod {
+			start, err := WorkPeriodStart(entry, wp)
+			if err != nil {
+				continue
+			}
+			ref := WorkPeriodRef{EntryIndex: i, PeriodIndex: j, Start: start}
+			if !IsOpenWorkPeriod(wp) {
+				if ref.End, err = WorkPeriodEnd(entry, wp); err != nil {
+					continue
+				}
+			}
+			refs = append(refs, ref)
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].Start.Before(refs[j].Start)
+	})
+	return refs
+}
+
+// Returns the work periods of a builder in order of their start, as by SortedWorkPeriods. An empty builder matches work
+// periods not attributed to anyone.
+func BuilderWorkPeriods(logs []*protos.BuildLogEntry, builder string) []WorkPeriodRef {
+	var refs []WorkPeriodRef
+	for _, ref := range SortedWorkPeriods(logs) {
+		if ref.Period(logs).Builder == builder {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// Returns the session of work among a builder's work periods, as by CurrentSession
+func sessionOf(logs []*protos.BuildLogEntry, refs []WorkPeriodRef) []WorkPeriodRef {
+	last := -1
+	for i := len(refs) - 1; i >= 0; i-- {
+		if IsOpenWorkPeriod(refs[i].Period(logs)) {
+			last = i
+			break
+		}
+	}
+	if last < 0 && len(refs) > 0 && refs[len(refs)-1].Period(logs).Paused {
+		last = len(refs) - 1
+	}
+	if last < 0 {
+		return nil
+	}
+	first := last
+	for first > 0 && refs[first-1].Period(logs).Paused {
+		first--
+	}
+	return refs[first : last+1]
+}
+
+// Returns the work periods of the builder's ongoing or paused session of work: their most recently started work period
+// that has not been stopped, wherever it falls among their work periods, or else their most recent work period if it was
+// paused, along with those preceding it that were paused rather than stopped. Returns nil if they are not working.
+// Start, stop, pause, resume, status and the timers all go by this, such that they agree on whether work is ongoing.
+func CurrentSession(logs []*protos.BuildLogEntry, builder string) []WorkPeriodRef {
+	return sessionOf(logs, BuilderWorkPeriods(logs, builder))
+}
+
+// Returns the builders with an ongoing or paused session of work, in alphabetical order. Each has their own session,
+// such that several builders may work at once.
+func ActiveBuilders(logs []*protos.BuildLogEntry) []string {
+	byBuilder := map[string][]WorkPeriodRef{}
+	for _, ref := range SortedWorkPeriods(logs) {
+		b := ref.Period(logs).Builder
+		byBuilder[b] = append(byBuilder[b], ref)
+	}
+	var builders []string
+	for b, refs := range byBuilder {
+		if len(sessionOf(logs, refs)) > 0 {
+			builders = append(builders, b)
+		}
+	}
+	sort.Strings(builders)
+	return builders
+}
+
+// Finds the builder's ongoing work period, as by CurrentSession, regardless of which day it was started on
+func FindOpenWorkPeriod(logs []*protos.BuildLogEntry, builder string) (exists bool, entryIndex int, periodIndex int) {
+	session := CurrentSession(logs, builder)
+	if len(session) == 0 {
+		return false, -1, -1
+	}
+	last := session[len(session)-1]
+	if !IsOpenWorkPeriod(last.Period(logs)) {
+		return false, -1, -1
+	}
+	return true, last.EntryIndex, last.PeriodIndex
+}
+
+// Finds the paused work period at which the builder's session of work was paused, as by CurrentSession, if it has
+// not since been resumed or stopped
+func FindPausedWorkPeriod(logs []*protos.BuildLogEntry, builder string) (exists bool, entryIndex int, periodIndex int) {
+	session := CurrentSession(logs, builder)
+	if len(session) == 0 {
+		return false, -1, -1
+	}
+	last := session[len(session)-1]
+	if IsOpenWorkPeriod(last.Period(logs)) {
+		return false, -1, -1
+	}
+	return true, last.EntryIndex, last.PeriodIndex
+}
+
+// Pauses the open work period at logs[entryIndex].WorkPeriod[periodIndex] at the specified time, such that the
+// session may later be resumed
+func PauseWorkPeriod(logs []*protos.BuildLogEntry, entryIndex int, periodIndex int, end time.Time, note string) ([]*protos.BuildLogEntry, uint32, error) {
+	logs, total, err := CloseWorkPeriod(logs, entryIndex, periodIndex, end)
+	if err != nil {
+		return nil, 0, err
+	}
+	// A work period that crossed midnight ends with the segment on the last day, which is not necessarily the builder's
+	// latest work period if it was left open while they logged other work
+	end = end.Truncate(time.Minute)
+	refs := BuilderWorkPeriods(logs, logs[entryIndex].WorkPeriod[periodIndex].Builder)
+	for i := len(refs) - 1; i >= 0; i-- {
+		if refs[i].End.Equal(end) {
+			wp := refs[i].Period(logs)
+			wp.Paused = true
+			wp.BreakNote = note
+			break
+		}
+	}
+	return logs, total, nil
+}
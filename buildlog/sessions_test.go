@@ -0,0 +1,95 @@
+package buildlog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+func testWorkPeriod(builder string, start time.Time, end time.Time) *protos.TimePeriod {
+	wp := NewWorkPeriod(start, end)
+	wp.Builder = builder
+	return wp
+}
+
+func TestCurrentSessionOpenPeriodNotLatest(t *testing.T) {
+	stale := time.Date(2024, time.December, 23, 13, 28, 0, 0, time.UTC)
+	later := time.Date(2025, time.January, 19, 9, 0, 0, 0, time.UTC)
+	logs := []*protos.BuildLogEntry{
+		{Id: "2024-Dec-23", Date: "2024-Dec-23", Assembly: "fuselage", WorkPeriod: []*protos.TimePeriod{
+			testWorkPeriod("Craig", stale, time.Time{}),
+		}},
+		{Id: "2025-Jan-19", Date: "2025-Jan-19", Assembly: "fuselage", WorkPeriod: []*protos.TimePeriod{
+			testWorkPeriod("Craig", later, later.Add(2*time.Hour)),
+			testWorkPeriod("Alex", later, later.Add(time.Hour)),
+		}},
+	}
+
+	session := CurrentSession(logs, "Craig")
+	if len(session) != 1 || session[0].EntryIndex != 0 || session[0].PeriodIndex != 0 {
+		t.Errorf("Craig's session is %v, expected the open work period on 2024-Dec-23", session)
+	}
+	if open, ei, pi := FindOpenWorkPeriod(logs, "Craig"); !open || ei != 0 || pi != 0 {
+		t.Errorf("Found open work period %v at %d/%d, expected the work period on 2024-Dec-23", open, ei, pi)
+	}
+	if paused, _, _ := FindPausedWorkPeriod(logs, "Craig"); paused {
+		t.Error("Found a paused work period of Craig, expected none")
+	}
+	if builders := ActiveBuilders(logs); len(builders) != 1 || builders[0] != "Craig" {
+		t.Errorf("Active builders are %v, expected [Craig]", builders)
+	}
+	if session := CurrentSession(logs, "Alex"); session != nil {
+		t.Errorf("Alex's session is %v, expected none", session)
+	}
+	timers := newTimers(logs)
+	if len(timers) != 1 || timers[0].Builder != "Craig" || timers[0].Paused || timers[0].SinceUnix != stale.Unix() {
+		t.Errorf("Timers are %v, expected Craig's since %s", timers, stale)
+	}
+
+	logs, _, err := CloseWorkPeriod(logs, 0, 0, stale.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builders := ActiveBuilders(logs); len(builders) != 0 {
+		t.Errorf("Active builders are %v after stopping, expected none", builders)
+	}
+	if timers := newTimers(logs); len(timers) != 0 {
+		t.Errorf("Timers are %v after stopping, expected none", timers)
+	}
+}
+
+func TestCurrentSessionPaused(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	logs := []*protos.BuildLogEntry{
+		{Id: "2024-Mar-01", Date: "2024-Mar-01", Assembly: "fuselage", WorkPeriod: []*protos.TimePeriod{
+			testWorkPeriod("Craig", start.Add(-2*time.Hour), start.Add(-time.Hour)),
+			testWorkPeriod("Craig", start, time.Time{}),
+		}},
+	}
+	logs, _, err := PauseWorkPeriod(logs, 0, 1, start.Add(time.Hour), "lunch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paused, ei, pi := FindPausedWorkPeriod(logs, "Craig"); !paused || ei != 0 || pi != 1 {
+		t.Errorf("Found paused work period %v at %d/%d, expected 0/1", paused, ei, pi)
+	}
+	if open, _, _ := FindOpenWorkPeriod(logs, "Craig"); open {
+		t.Error("Found an open work period of Craig while paused")
+	}
+	if session := CurrentSession(logs, "Craig"); len(session) != 1 {
+		t.Errorf("Session has %d work periods, expected only the paused one", len(session))
+	}
+
+	// Resumed, the session spans both work periods
+	resumed := start.Add(90 * time.Minute)
+	logs[0].WorkPeriod = append(logs[0].WorkPeriod, testWorkPeriod("Craig", resumed, time.Time{}))
+	session := CurrentSession(logs, "Craig")
+	if len(session) != 2 || !session[0].Start.Equal(start) || !session[1].Start.Equal(resumed) {
+		t.Errorf("Session is %v, expected the paused and resumed work periods", session)
+	}
+	timers := newTimers(logs)
+	if len(timers) != 1 || timers[0].Paused || timers[0].SinceUnix != resumed.Unix() {
+		t.Errorf("Timers are %v, expected Craig's since %s", timers, resumed)
+	}
+}
@@ -0,0 +1,441 @@
+package buildlog
+
+import (
+	"encoding/xml"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+const (
+	siteTemplatesDir = "templates/site"
+	siteAssetsDir    = "assets"
+	siteFeedEntries  = 20
+)
+
+// Built-in site templates, executed once per page of the corresponding kind. Each may be overridden by a file of
+// the same name in the theme dir, which may also define its own "header" and "footer" in layout.html.tmpl.
+var siteTemplates = []string{
+	"layout.html.tmpl",
+	"index.html.tmpl",
+	"entry.html.tmpl",
+	"list.html.tmpl",
+	"hours.html.tmpl",
+	"feed.xml.tmpl",
+}
+
+type SiteEntry struct {
+	*protos.BuildLogEntry
+	Details string
+	Minutes int
+	// Time of the latest work on the log entry, for the feed
+	Updated time.Time
+	// Paths relative to the site root
+	URL         string
+	AssemblyURL string
+	MonthURL    string
+	Prev        *SiteEntry
+	Next        *SiteEntry
+	TagURLs     map[string]string
+	// Running total of work time across the entire build, including this entry
+	CumulativeMinutes int
+	Attachments       []*SiteAttachment
+}
+
+type SiteAttachment struct {
+	*protos.Attachment
+	// Path relative to the site root
+	URL   string
+	Image bool
+}
+
+// A page listing a subset of log entries, e.g., all log entries of an assembly, tag or month
+type SiteIndex struct {
+	Kind    string
+	Name    string
+	URL     string
+	Minutes int
+	Entries []*SiteEntry
+}
+
+type SiteHoursTotal struct {
+	HoursTotal
+	// Minutes as a percentage of the largest group, for bar charts
+	Percent int
+}
+
+func (e *SiteEntry) ID() string {
+	return LogEntryID(e.BuildLogEntry)
+}
+
+// Data model of the static site, shared by all pages
+type Site struct {
+	Title        string
+	BaseURL      string
+	Generated    time.Time
+	TotalMinutes int
+	// Log entries in chronological order
+	Entries    []*SiteEntry
+	Assemblies []*SiteIndex
+	Tags       []*SiteIndex
+	Months     []*SiteIndex
+	// Hours dashboard, keyed by report grouping
+	Hours map[string][]SiteHoursTotal
+}
+
+// Data passed to each page template
+type SitePage struct {
+	*Site
+	// Relative path from the page to the site root, e.g., "../"
+	Root  string
+	Entry *SiteEntry
+	Index *SiteIndex
+}
+
+// Data passed to the "entries" and "indexes" templates shared by pages
+type SiteListing struct {
+	Root    string
+	Entries []*SiteEntry
+	Indexes []*SiteIndex
+}
+
+func (p *SitePage) EntryList(entries []*SiteEntry) *SiteListing {
+	return &SiteListing{Root: p.Root, Entries: entries}
+}
+
+func (p *SitePage) IndexList(indexes []*SiteIndex) *SiteListing {
+	return &SiteListing{Root: p.Root, Indexes: indexes}
+}
+
+// Converts a name to a form safe for use in file names and URLs
+func Slug(name string) string {
+	return strings.Join(Tokenize(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return r
+		}
+		return ' '
+	}, name)), "-")
+}
+
+// Escapes text for XML, replacing characters that XML does not allow
+func escapeXML(text string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// Returns the latest entries first, limited to n
+func (s *Site) Recent(n int) []*SiteEntry {
+	var recent []*SiteEntry
+	for i := len(s.Entries) - 1; i >= 0 && len(recent) < n; i-- {
+		recent = append(recent, s.Entries[i])
+	}
+	return recent
+}
+
+func (s *Site) FirstDate() string {
+	if len(s.Entries) == 0 {
+		return ""
+	}
+	return s.Entries[0].Date
+}
+
+func (s *Site) LastDate() string {
+	if len(s.Entries) == 0 {
+		return ""
+	}
+	return s.Entries[len(s.Entries)-1].Date
+}
+
+// Returns the log entries published in the feed
+func (s *Site) Feed() []*SiteEntry {
+	return s.Recent(siteFeedEntries)
+}
+
+func (s *Site) Updated() time.Time {
+	var updated time.Time
+	for _, e := range s.Entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+	}
+	return updated
+}
+
+func siteIndex(indexes map[string]*SiteIndex, list *[]*SiteIndex, kind string, dir string, name string) *SiteIndex {
+	key := kind + "/" + name
+	index, exists := indexes[key]
+	if !exists {
+		index = &SiteIndex{
+			Kind: kind,
+			Name: name,
+			URL:  path.Join(dir, Slug(name)+".html"),
+		}
+		indexes[key] = index
+		*list = append(*list, index)
+	}
+	return index
+}
+
+func logEntryUpdated(entry *protos.BuildLogEntry, date time.Time) time.Time {
+	updated := date
+	for _, wp := range entry.WorkPeriod {
+		if IsOpenWorkPeriod(wp) {
+			continue
+		}
+		if end, err := WorkPeriodEnd(entry, wp); err == nil && end.After(updated) {
+			updated = end
+		}
+	}
+	return updated
+}
+
+func NewSite(root string, logs []*protos.BuildLogEntry, title string, baseURL string) (*Site, error) {
+	site := &Site{
+		Title:     title,
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		Generated: time.Now(),
+		Hours:     map[string][]SiteHoursTotal{},
+	}
+	indexes := map[string]*SiteIndex{}
+	var prev *SiteEntry
+	for _, log := range logs {
+		date, err := ParseDateOfLog(log)
+		if err != nil {
+			return nil, err
+		}
+		details, err := ReadLogDetails(root, log)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		minutes := LogEntryMinutes(log)
+		site.TotalMinutes += minutes
+		entry := &SiteEntry{
+			BuildLogEntry:     log,
+			Details:           details,
+			Minutes:           minutes,
+			Updated:           logEntryUpdated(log, date),
+			URL:               path.Join("entries", Slug(LogEntryID(log))+".html"),
+			Prev:              prev,
+			TagURLs:           map[string]string{},
+			CumulativeMinutes: site.TotalMinutes,
+		}
+		for _, a := range log.Attachment {
+			// Missing attachments are reported by validate rather than breaking the site
+			if exists, _ := FileExists(AttachmentPath(root, a)); !exists {
+				continue
+			}
+			entry.Attachments = append(entry.Attachments, &SiteAttachment{
+				Attachment: a,
+				URL:        path.Join("attachments", a.File),
+				Image:      IsImageAttachment(a),
+			})
+		}
+		if prev != nil {
+			prev.Next = entry
+		}
+		prev = entry
+		site.Entries = append(site.Entries, entry)
+
+		assembly := siteIndex(indexes, &site.Assemblies, "assembly", "assemblies", log.Assembly)
+		assembly.Entries = append(assembly.Entries, entry)
+		assembly.Minutes += minutes
+		entry.AssemblyURL = assembly.URL
+		month := siteIndex(indexes, &site.Months, "month", "archive", date.Format(MonthLayout))
+		month.Entries = append(month.Entries, entry)
+		month.Minutes += minutes
+		entry.MonthURL = month.URL
+		for _, t := range log.Tags {
+			tag := siteIndex(indexes, &site.Tags, "tag", "tags", t)
+			tag.Entries = append(tag.Entries, entry)
+			tag.Minutes += minutes
+			entry.TagURLs[t] = tag.URL
+		}
+	}
+
+	groupings := []string{"assembly", "month", "year"}
+	if HasAttributedWork(logs) {
+		groupings = append(groupings, "builder")
+	}
+	for _, grouping := range groupings {
+		totals, err := HoursReport(logs, grouping)
+		if err != nil {
+			return nil, err
+		}
+		largest := 0
+		for _, t := range totals {
+			if t.Minutes > largest {
+				largest = t.Minutes
+			}
+		}
+		for _, t := range totals {
+			percent := 0
+			if largest > 0 {
+				percent = 100 * t.Minutes / largest
+			}
+			site.Hours[grouping] = append(site.Hours[grouping], SiteHoursTotal{t, percent})
+		}
+	}
+	return site, nil
+}
+
+// The HTML templates of the pages of a site, which escape their output, and the text template of its feed
+type SiteTemplates struct {
+	pages *htmltemplate.Template
+	feed  *template.Template
+}
+
+func (t *SiteTemplates) ExecuteTemplate(w io.Writer, name string, data any) error {
+	if strings.HasSuffix(name, ".html.tmpl") {
+		return t.pages.ExecuteTemplate(w, name, data)
+	}
+	return t.feed.ExecuteTemplate(w, name, data)
+}
+
+// Loads the built-in site templates, overridden by any of the same name in themeDir
+func LoadSiteTemplates(themeDir string) (*SiteTemplates, error) {
+	funcs := map[string]any{
+		"slug": Slug,
+		"list": func(items ...string) []string { return items },
+		"xml":  escapeXML,
+	}
+	tmpl := &SiteTemplates{
+		pages: NewHTMLTemplate("site").Funcs(funcs),
+		feed:  NewTemplate("feed").Funcs(funcs),
+	}
+	for _, name := range siteTemplates {
+		var text []byte
+		var err error
+		f := filepath.Join(themeDir, name)
+		if exists, _ := FileExists(f); len(themeDir) > 0 && exists {
+			text, err = os.ReadFile(f)
+		} else {
+			text, err = builtinTemplates.ReadFile(path.Join(siteTemplatesDir, name))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(name, ".html.tmpl") {
+			_, err = tmpl.pages.New(name).Parse(string(text))
+		} else {
+			_, err = tmpl.feed.New(name).Parse(string(text))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+func writeSiteFile(outDir string, rel string, data []byte) error {
+	f := filepath.Join(outDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f, data, 0644)
+}
+
+func executeSitePage(tmpl *SiteTemplates, outDir string, name string, rel string, page *SitePage) error {
+	page.Root = strings.Repeat("../", strings.Count(rel, "/"))
+	var buf strings.Builder
+	if err := tmpl.ExecuteTemplate(&buf, name, page); err != nil {
+		return fmt.Errorf("Could not render %s\n%s", rel, err.Error())
+	}
+	return writeSiteFile(outDir, rel, []byte(buf.String()))
+}
+
+// Copies the built-in assets, then those in the assets dir of themeDir, to the site
+func copySiteAssets(outDir string, themeDir string) (int, error) {
+	count := 0
+	assets, err := fs.Sub(builtinTemplates, path.Join(siteTemplatesDir, siteAssetsDir))
+	if err != nil {
+		return 0, err
+	}
+	sources := []fs.FS{assets}
+	if len(themeDir) > 0 {
+		if exists, _ := FileExists(filepath.Join(themeDir, siteAssetsDir)); exists {
+			sources = append(sources, os.DirFS(filepath.Join(themeDir, siteAssetsDir)))
+		}
+	}
+	for _, src := range sources {
+		err := fs.WalkDir(src, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := fs.ReadFile(src, p)
+			if err != nil {
+				return err
+			}
+			count++
+			return writeSiteFile(outDir, path.Join(siteAssetsDir, p), data)
+		})
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+func copySiteAttachments(root string, outDir string, site *Site) (int, error) {
+	count := 0
+	for _, e := range site.Entries {
+		for _, a := range e.Attachments {
+			data, err := os.ReadFile(AttachmentPath(root, a.Attachment))
+			if err != nil {
+				return count, err
+			}
+			if err := writeSiteFile(outDir, a.URL, data); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Generates the static site in outDir, returning the number of files written
+func WriteSite(root string, outDir string, tmpl *SiteTemplates, site *Site, themeDir string) (int, error) {
+	count := 0
+	write := func(name string, rel string, entry *SiteEntry, index *SiteIndex) error {
+		count++
+		return executeSitePage(tmpl, outDir, name, rel, &SitePage{Site: site, Entry: entry, Index: index})
+	}
+	if err := write("index.html.tmpl", "index.html", nil, nil); err != nil {
+		return count, err
+	}
+	if err := write("hours.html.tmpl", "hours.html", nil, nil); err != nil {
+		return count, err
+	}
+	if err := write("feed.xml.tmpl", "feed.xml", nil, nil); err != nil {
+		return count, err
+	}
+	for _, e := range site.Entries {
+		if err := write("entry.html.tmpl", e.URL, e, nil); err != nil {
+			return count, err
+		}
+	}
+	for _, list := range [][]*SiteIndex{site.Assemblies, site.Tags, site.Months} {
+		for _, index := range list {
+			if err := write("list.html.tmpl", index.URL, nil, index); err != nil {
+				return count, err
+			}
+		}
+	}
+	attachments, err := copySiteAttachments(root, outDir, site)
+	count += attachments
+	if err != nil {
+		return count, err
+	}
+	assets, err := copySiteAssets(outDir, themeDir)
+	return count + assets, err
+}
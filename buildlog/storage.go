@@ -0,0 +1,234 @@
+package buildlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const DefaultStorage = "textproto"
+
+// Encoding of the logs metadata file
+type LogStorage interface {
+	// As set by storage in the project config
+	Name() string
+	// Name of the logs metadata file within the logs dir
+	FileName() string
+	Marshal(logs *protos.BuildLogs) ([]byte, error)
+	Unmarshal(data []byte, logs *protos.BuildLogs) error
+}
+
+var logStorages = []LogStorage{textprotoStorage{}, jsonStorage{}, binaryStorage{}}
+
+func LogStorageNames() []string {
+	var names []string
+	for _, s := range logStorages {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// Finds a storage by name; an empty name is the default storage
+func FindLogStorage(name string) (LogStorage, error) {
+	if len(name) == 0 {
+		name = DefaultStorage
+	}
+	for _, s := range logStorages {
+		if s.Name() == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("Storage must be one of:\n  %s", strings.Join(LogStorageNames(), "\n  "))
+}
+
+// Returns the storage of a logs metadata file, or of a backup of one, by its name
+func LogStorageOfFile(f string) LogStorage {
+	base := filepath.Base(f)
+	for _, s := range logStorages {
+		if base == s.FileName() || strings.HasPrefix(base, s.FileName()+".") {
+			return s
+		}
+	}
+	return textprotoStorage{}
+}
+
+// Returns the storage configured by the project at root, falling back to the default storage if the project config
+// cannot be read
+func ProjectLogStorage(root string) LogStorage {
+	if config, err := ReadProjectConfig(root); err == nil {
+		if s, err := FindLogStorage(config.Storage); err == nil {
+			return s
+		}
+	}
+	return textprotoStorage{}
+}
+
+// Returns an error if f does not exist but the logs are stored alongside it in another format, such as after
+// changing the storage of the project config by hand rather than by 'convert'
+func checkLogStorage(f string) error {
+	for _, s := range logStorages {
+		other := filepath.Join(filepath.Dir(f), s.FileName())
+		if other == f {
+			continue
+		}
+		if exists, err := FileExists(other); err == nil && exists {
+			return fmt.Errorf("Logs are stored in %s rather than %s; run 'convert -to %s' to change the storage of the logs",
+				other, filepath.Base(f), LogStorageOfFile(f).Name())
+		}
+	}
+	return nil
+}
+
+type textprotoStorage struct{}
+
+func (textprotoStorage) Name() string {
+	return "textproto"
+}
+
+func (textprotoStorage) FileName() string {
+	return "buildlog.textproto"
+}
+
+func (textprotoStorage) Marshal(logs *protos.BuildLogs) ([]byte, error) {
+	return []byte(proto.MarshalTextString(logs)), nil
+}
+
+func (textprotoStorage) Unmarshal(data []byte, logs *protos.BuildLogs) error {
+	return proto.UnmarshalText(string(data), logs)
+}
+
+// Uses the field names of the proto definitions, as textproto does, with one field per line such that diffs are
+// readable
+type jsonStorage struct{}
+
+func (jsonStorage) Name() string {
+	return "json"
+}
+
+func (jsonStorage) FileName() string {
+	return "buildlog.json"
+}
+
+func (jsonStorage) Marshal(logs *protos.BuildLogs) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(logs)
+	if err != nil {
+		return nil, err
+	}
+	// Reindented since protojson deliberately varies its whitespace
+	var b bytes.Buffer
+	if err := json.Indent(&b, data, "", "  "); err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func (jsonStorage) Unmarshal(data []byte, logs *protos.BuildLogs) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return protojson.Unmarshal(data, logs)
+}
+
+// Fastest to read and write, at the cost of readable diffs
+type binaryStorage struct{}
+
+func (binaryStorage) Name() string {
+	return "binary"
+}
+
+func (binaryStorage) FileName() string {
+	return "buildlog.binpb"
+}
+
+func (binaryStorage) Marshal(logs *protos.BuildLogs) ([]byte, error) {
+	return proto.Marshal(logs)
+}
+
+func (binaryStorage) Unmarshal(data []byte, logs *protos.BuildLogs) error {
+	return proto.Unmarshal(data, logs)
+}
+
+// Converts the logs of the project at root to another storage, verifying that they read back unchanged before
+// removing the logs metadata file of the prior storage, a copy of which is kept among the backups. Returns the new
+// logs metadata file.
+func ConvertLogStorage(root string, to LogStorage) (string, error) {
+	from := ProjectLogStorage(root)
+	if from.Name() == to.Name() {
+		return "", fmt.Errorf("Logs are already stored as %s", to.Name())
+	}
+	src := LogsPath(root)
+	dst := filepath.Join(LogsDir(root), to.FileName())
+	unlock, err := LockFile(src)
+	if err != nil {
+		return "", fmt.Errorf("Could not lock %s\n%s", src, err.Error())
+	}
+	defer unlock()
+
+	logs, err := ReadLogs(src)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("Could not open logs metadata from %s\n%s", src, err.Error())
+	}
+	data, err := to.Marshal(logs)
+	if err != nil {
+		return "", err
+	}
+	converted := &protos.BuildLogs{}
+	if err := to.Unmarshal(data, converted); err != nil || !proto.Equal(logs, converted) {
+		return "", fmt.Errorf("Logs would not be converted to %s without loss", to.Name())
+	}
+	if exists, err := FileExists(dst); err != nil {
+		return "", err
+	} else if exists {
+		if err := BackupFile(dst); err != nil {
+			return "", fmt.Errorf("Could not back up %s\n%s", dst, err.Error())
+		}
+	}
+	if err := WriteFileAtomic(dst, data, 0644); err != nil {
+		return "", err
+	}
+	if err := setConfigStorage(root, to.Name()); err != nil {
+		os.Remove(dst)
+		return "", err
+	}
+	if err := BackupFile(src); err != nil {
+		return "", fmt.Errorf("Could not back up %s\n%s", src, err.Error())
+	}
+	if err := os.Remove(src); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return dst, nil
+}
+
+// Sets storage in the project config, editing its text in place such that comments and formatting are retained
+func setConfigStorage(root string, name string) error {
+	f := ConfigPath(root)
+	text, err := ReadFile(f)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	setting := fmt.Sprintf("storage: %q", name)
+	var lines []string
+	found := false
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "storage:") {
+			line, found = setting, true
+		}
+		lines = append(lines, line)
+	}
+	if !found {
+		lines = append(lines, setting)
+	}
+	updated := strings.TrimLeft(strings.Join(lines, "\n"), "\n") + "\n"
+	if err := proto.UnmarshalText(updated, &protos.ProjectConfig{}); err != nil {
+		return fmt.Errorf("Could not set storage in %s, set it by hand to %q\n%s", f, name, err.Error())
+	}
+	return WriteFileAtomic(f, []byte(updated), 0644)
+}
@@ -0,0 +1,110 @@
+package buildlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+func testLogs() *protos.BuildLogs {
+	return &protos.BuildLogs{
+		LogEntry: []*protos.BuildLogEntry{
+			{
+				Id:          "2024-Mar-01",
+				Assembly:    "fuselage",
+				Subassembly: []string{"longerons"},
+				Date:        "2024-Mar-01",
+				Title:       "Rivet \"upper\" longerons",
+				Tags:        []string{"riveting"},
+				DetailsFile: "2024-Mar/2024-Mar-01.md",
+				WorkPeriod: []*protos.TimePeriod{
+					{
+						StartTime:   "9:00AM",
+						EndTime:     "10:30AM",
+						DurationMin: 90,
+						Start:       "2024-03-01T09:00:00-07:00",
+						End:         "2024-03-01T10:30:00-07:00",
+						TimeZone:    "America/Denver",
+						Builder:     "Craig",
+						Helper:      []string{"Sam"},
+					},
+					{StartTime: "1:00PM"},
+				},
+				Attachment: []*protos.Attachment{
+					{File: "2024-Mar/2024-Mar-01/IMG_1234.jpg", Sha256: "abc123", Caption: "Longerons"},
+				},
+			},
+			{Assembly: "left wing", Date: "2024-Mar-02", Title: "Drill skins"},
+		},
+	}
+}
+
+func TestLogStorageRoundTrip(t *testing.T) {
+	for _, name := range LogStorageNames() {
+		s, err := FindLogStorage(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs := testLogs()
+		data, err := s.Marshal(logs)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		read := &protos.BuildLogs{}
+		if err := s.Unmarshal(data, read); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if !proto.Equal(logs, read) {
+			t.Errorf("%s: logs read back as %v, expected %v", name, read, logs)
+		}
+		if LogStorageOfFile(filepath.Join("log", s.FileName())).Name() != name {
+			t.Errorf("%s: %s is not recognized as %s", name, s.FileName(), name)
+		}
+	}
+	if _, err := FindLogStorage("yaml"); err == nil {
+		t.Error("Found an unknown storage")
+	}
+}
+
+func TestConvertLogStorage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(ConfigPath(root), []byte("# Project config\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logs := testLogs()
+	if err := WriteLogs(LogsPath(root), logs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"json", "binary", "textproto"} {
+		to, err := FindLogStorage(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prior := LogsPath(root)
+		f, err := ConvertLogStorage(root, to)
+		if err != nil {
+			t.Fatalf("Converting to %s: %s", name, err)
+		}
+		if f != LogsPath(root) || filepath.Base(f) != to.FileName() {
+			t.Errorf("Converted to %s, expected the logs path %s of %s", f, LogsPath(root), name)
+		}
+		if exists, err := FileExists(prior); err != nil || exists {
+			t.Errorf("Logs stored before converting to %s remain at %s", name, prior)
+		}
+		read, err := ReadLogs(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(logs, read) {
+			t.Errorf("Logs converted to %s read back as %v, expected %v", name, read, logs)
+		}
+	}
+
+	if _, err := ConvertLogStorage(root, textprotoStorage{}); err == nil {
+		t.Error("Converting to the current storage succeeded, expected an error")
+	}
+}
@@ -0,0 +1,115 @@
+package buildlog
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yuin/goldmark"
+)
+
+// Functions available to all templates, e.g., {{.DurationMin | duration}}
+var TemplateFuncs = template.FuncMap{
+	"duration": func(minutes any) (string, error) {
+		m, err := templateInt(minutes)
+		return FormatDurationMin(m), err
+	},
+	"hours": func(minutes any) (string, error) {
+		m, err := templateInt(minutes)
+		return FormatHours(m), err
+	},
+	"date":     FormatTemplateDate,
+	"markdown": MarkdownToHTML,
+	"image":    IsImageAttachment,
+	"join":     strings.Join,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+}
+
+// Either a text or an HTML template
+type ExecutableTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Functions available to HTML templates, as TemplateFuncs except that markdown returns HTML that is not escaped again
+func htmlTemplateFuncs() htmltemplate.FuncMap {
+	funcs := htmltemplate.FuncMap{}
+	for name, f := range TemplateFuncs {
+		funcs[name] = f
+	}
+	funcs["markdown"] = MarkdownToSafeHTML
+	return funcs
+}
+
+// Converts any integer, e.g., TimePeriod.DurationMin, to an int
+func templateInt(v any) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case int64:
+		return int(n), nil
+	case uint32:
+		return int(n), nil
+	case uint64:
+		return int(n), nil
+	}
+	return 0, fmt.Errorf("Expected an integer, got %T", v)
+}
+
+// Formats a time, a log entry date, e.g., 2006-Jan-02, or an RFC 3339 timestamp using layout, e.g.,
+// {{.Date | date "January 2, 2006"}}
+func FormatTemplateDate(layout string, v any) (string, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case string:
+		for _, l := range []string{DateLayout, time.RFC3339} {
+			if d, err := time.Parse(l, t); err == nil {
+				return d.Format(layout), nil
+			}
+		}
+		return "", fmt.Errorf("Cannot parse %q as a date", t)
+	}
+	return "", fmt.Errorf("Expected a date, got %T", v)
+}
+
+func FormatDurationMin(minutes int) string {
+	if minutes == 0 {
+		return "0m"
+	}
+	return strings.TrimSuffix((time.Duration(minutes) * time.Minute).String(), "0s")
+}
+
+func FormatHours(minutes int) string {
+	return strconv.FormatFloat(float64(minutes)/60, 'f', 1, 64)
+}
+
+func MarkdownToHTML(md string) (string, error) {
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(md), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Raw HTML within the markdown is omitted, as by MarkdownToHTML, such that the result is safe to include as is
+func MarkdownToSafeHTML(md string) (htmltemplate.HTML, error) {
+	html, err := MarkdownToHTML(md)
+	return htmltemplate.HTML(html), err
+}
+
+func NewTemplate(name string) *template.Template {
+	return template.New(name).Funcs(TemplateFuncs)
+}
+
+// Constructs a template that escapes its output as HTML in context
+func NewHTMLTemplate(name string) *htmltemplate.Template {
+	return htmltemplate.New(name).Funcs(htmlTemplateFuncs())
+}
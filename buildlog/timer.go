@@ -0,0 +1,119 @@
+package buildlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	timerCacheFile = ".timer.cache"
+
+	// Icon, assembly and time elapsed, e.g., "⏱ fuselage 1h12m"
+	DefaultTimerFormat = "%i %a %d"
+)
+
+func TimerCachePath(root string) string {
+	return filepath.Join(LogsDir(root), timerCacheFile)
+}
+
+// Returns a timer for each ongoing or paused session of work. The logs are only read if they changed since the timers
+// were last cached, such that this is cheap enough to call on every redraw of a shell prompt.
+func ReadTimers(root string) ([]*protos.Timer, error) {
+	f := LogsPath(root)
+	fi, err := os.Stat(f)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cache := TimerCachePath(root)
+	if data, err := os.ReadFile(cache); err == nil {
+		cached := &protos.TimerCache{}
+		// A corrupt cache is simply replaced
+		if err := proto.Unmarshal(data, cached); err == nil && cached.ModTimeUnixNano == fi.ModTime().UnixNano() && cached.Size == fi.Size() {
+			return cached.Timer, nil
+		}
+	}
+	logs, err := ReadLogs(f)
+	if err != nil {
+		return nil, err
+	}
+	cached := &protos.TimerCache{
+		ModTimeUnixNano: fi.ModTime().UnixNano(),
+		Size:            fi.Size(),
+		Timer:           newTimers(logs.LogEntry),
+	}
+	if data, err := proto.Marshal(cached); err == nil {
+		// The cache is only an optimization, so failing to save it is not an error
+		WriteFileAtomic(cache, data, 0644)
+	}
+	return cached.Timer, nil
+}
+
+func newTimers(logs []*protos.BuildLogEntry) []*protos.Timer {
+	var timers []*protos.Timer
+	for _, builder := range ActiveBuilders(logs) {
+		if open, ei, pi := FindOpenWorkPeriod(logs, builder); open {
+			if start, err := WorkPeriodStart(logs[ei], logs[ei].WorkPeriod[pi]); err == nil {
+				timers = append(timers, &protos.Timer{Builder: builder, Assembly: logs[ei].Assembly, SinceUnix: start.Unix()})
+			}
+		} else if paused, ei, pi := FindPausedWorkPeriod(logs, builder); paused {
+			if end, err := WorkPeriodEnd(logs[ei], logs[ei].WorkPeriod[pi]); err == nil {
+				timers = append(timers, &protos.Timer{Builder: builder, Paused: true, Assembly: logs[ei].Assembly, SinceUnix: end.Unix()})
+			}
+		}
+	}
+	return timers
+}
+
+// Formats timers for a shell prompt or status bar, separated by two spaces, as by FormatTimer
+func FormatTimers(format string, timers []*protos.Timer, now time.Time) string {
+	var formatted []string
+	for _, timer := range timers {
+		formatted = append(formatted, FormatTimer(format, timer, now))
+	}
+	return strings.Join(formatted, "  ")
+}
+
+// Formats a timer for a shell prompt or status bar, replacing %a with the assembly, %b with the builder, %d with the
+// time elapsed since the work period started or work was paused, %i with an icon for whether working or paused, and
+// %% with %
+func FormatTimer(format string, timer *protos.Timer, now time.Time) string {
+	icon := "⏱"
+	if timer.Paused {
+		icon = "⏸"
+	}
+	elapsed := int(now.Sub(time.Unix(timer.SinceUnix, 0)).Minutes())
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'a':
+			b.WriteString(timer.Assembly)
+		case 'b':
+			b.WriteString(timer.Builder)
+		case 'd':
+			b.WriteString(FormatDurationMin(elapsed))
+		case 'i':
+			b.WriteString(icon)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
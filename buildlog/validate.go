@@ -0,0 +1,260 @@
+package buildlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/protos"
+)
+
+var logDetailsFilePattern = regexp.MustCompile(`^\d{4}-[A-Z][a-z]{2}$`)
+
+// A consistency problem in the logs. EntryIndex and PeriodIndex locate the problem within the logs, or are -1 if
+// the problem is not specific to a log entry or work period.
+type Problem struct {
+	EntryIndex  int
+	PeriodIndex int
+	File        string
+	Message     string
+	// Repairs the problem in the logs it was found in; nil if the problem cannot be safely repaired automatically
+	Fix func() error
+}
+
+func (p *Problem) Fixable() bool {
+	return p.Fix != nil
+}
+
+type interval struct {
+	start   time.Time
+	end     time.Time
+	entry   int
+	index   int
+	builder string
+}
+
+// Checks the logs, and the details files of the project at root, for consistency
+func ValidateLogs(root string, logs []*protos.BuildLogEntry, config *protos.ProjectConfig, now time.Time) []*Problem {
+	var problems []*Problem
+	report := func(entry int, period int, fix func() error, format string, a ...any) {
+		problems = append(problems, &Problem{
+			EntryIndex:  entry,
+			PeriodIndex: period,
+			Message:     fmt.Sprintf(format, a...),
+			Fix:         fix,
+		})
+	}
+
+	orphans, err := unreferencedDetailsFiles(root, logs)
+	if err != nil {
+		report(-1, -1, nil, "Could not list details files: %s", err.Error())
+	}
+	// Log entries whose details file is missing, by the details files that do not belong to any log entry and
+	// match their date
+	missing := map[*Problem][]string{}
+	claims := map[string]int{}
+
+	ids := map[string]int{}
+	var prevDate time.Time
+	var periods []interval
+	for i, entry := range logs {
+		date, err := ParseDateOfLog(entry)
+		if err != nil {
+			report(i, -1, nil, "Invalid date %q", entry.Date)
+			continue
+		}
+		// Older log entries have no ID, which is not a problem
+		if len(entry.Id) > 0 {
+			if first, exists := ids[entry.Id]; exists {
+				report(i, -1, nil, "Duplicate ID %s, first used by log entry for %s", entry.Id, logs[first].Date)
+			} else {
+				ids[entry.Id] = i
+			}
+		}
+		if date.Before(prevDate) {
+			// Logs are sorted whenever they are written
+			report(i, -1, func() error { return nil }, "Log entry for %s is out of order, follows %s", entry.Date, FormatDateForLog(prevDate))
+		} else {
+			prevDate = date
+		}
+		if len(entry.Assembly) == 0 {
+			report(i, -1, nil, "No assembly")
+		} else if FindAssembly(config, entry.Assembly) == nil {
+			report(i, -1, nil, "Assembly %q is not configured in %s", entry.Assembly, ConfigFile)
+		}
+		if len(entry.WorkPeriod) == 0 {
+			report(i, -1, nil, "No work periods")
+		}
+
+		// Details file
+		if len(entry.DetailsFile) == 0 {
+			e := entry
+			report(i, -1, func() error {
+				e.DetailsFile = RelativeLogDetailsFile(date, LogEntryID(e))
+				return nil
+			}, "No details file")
+		} else if exists, err := FileExists(LogDetailsPath(root, entry)); err != nil {
+			report(i, -1, nil, "Could not check details file %s: %s", entry.DetailsFile, err.Error())
+		} else if !exists {
+			report(i, -1, nil, "Details file %s does not exist", entry.DetailsFile)
+			candidates := detailsFilesOfDate(orphans, entry.Date)
+			for _, f := range candidates {
+				claims[f]++
+			}
+			missing[problems[len(problems)-1]] = candidates
+		}
+
+		// Attachments
+		for _, a := range entry.Attachment {
+			if sum, err := FileSHA256(AttachmentPath(root, a)); os.IsNotExist(err) {
+				report(i, -1, nil, "Attachment %s does not exist", a.File)
+			} else if err != nil {
+				report(i, -1, nil, "Could not read attachment %s: %s", a.File, err.Error())
+			} else if sum != a.Sha256 {
+				report(i, -1, nil, "Attachment %s does not match its recorded checksum", a.File)
+			}
+		}
+
+		// Work periods
+		for j, wp := range entry.WorkPeriod {
+			if len(wp.Builder) > 0 && len(config.Builder) > 0 {
+				if FindBuilder(config, wp.Builder) == nil {
+					report(i, j, nil, "Builder %q is not configured in %s", wp.Builder, ConfigFile)
+				}
+			}
+			start, err := WorkPeriodStart(entry, wp)
+			if err != nil {
+				report(i, j, nil, "Invalid start of work period: %s", err.Error())
+				continue
+			}
+			if IsOpenWorkPeriod(wp) {
+				if TruncateToDate(start).Before(TruncateToDate(now)) {
+					// Excluded from the overlap check since the time at which it actually ended is unknown
+					report(i, j, nil, "Work period started at %s was never stopped", wp.StartTime)
+				} else {
+					periods = append(periods, interval{start, now, i, j, wp.Builder})
+				}
+				continue
+			}
+			end, err := WorkPeriodEnd(entry, wp)
+			if err != nil {
+				report(i, j, nil, "Invalid end of work period: %s", err.Error())
+				continue
+			}
+			if end.Before(start) {
+				report(i, j, nil, "Work period ends at %s, before it starts at %s", wp.EndTime, wp.StartTime)
+				continue
+			}
+			// Either the times or the duration may be the mistake
+			if expected := durationMin(end.Sub(start)); wp.DurationMin != expected {
+				report(i, j, nil, "Work period %s-%s has duration_min %d, expected %d", wp.StartTime, wp.EndTime, wp.DurationMin, expected)
+			}
+			periods = append(periods, interval{start, end, i, j, wp.Builder})
+		}
+	}
+
+	// A missing details file is only repaired by pointing the log entry at the one details file that matches its date
+	// and no other log entry
+	for p, candidates := range missing {
+		if len(candidates) == 1 && claims[candidates[0]] == 1 {
+			e, f := logs[p.EntryIndex], candidates[0]
+			p.Fix = func() error {
+				e.DetailsFile = f
+				return nil
+			}
+			p.Message += fmt.Sprintf(", but %s does", f)
+		}
+	}
+
+	// Overlapping work periods, which are only a problem for the same builder since builders may work concurrently
+	sort.SliceStable(periods, func(i, j int) bool {
+		if periods[i].builder != periods[j].builder {
+			return periods[i].builder < periods[j].builder
+		}
+		return periods[i].start.Before(periods[j].start)
+	})
+	for k := 1; k < len(periods); k++ {
+		// Compare against whichever earlier work period by the same builder ends last
+		prev, cur := periods[k-1], periods[k]
+		if cur.builder != prev.builder {
+			continue
+		}
+		if k > 1 && periods[k-2].builder == prev.builder && periods[k-2].end.After(prev.end) {
+			periods[k-1] = periods[k-2]
+			prev = periods[k-1]
+		}
+		if cur.start.Before(prev.end) {
+			report(cur.entry, cur.index, nil, "Work period %s-%s overlaps work period %s-%s on %s",
+				logs[cur.entry].WorkPeriod[cur.index].StartTime, logs[cur.entry].WorkPeriod[cur.index].EndTime,
+				logs[prev.entry].WorkPeriod[prev.index].StartTime, logs[prev.entry].WorkPeriod[prev.index].EndTime,
+				logs[prev.entry].Date)
+		}
+	}
+
+	// Details files that do not belong to any log entry
+	for _, f := range orphans {
+		problems = append(problems, &Problem{
+			EntryIndex:  -1,
+			PeriodIndex: -1,
+			File:        filepath.Join(LogsDir(root), f),
+			Message:     "Details file does not belong to any log entry",
+		})
+	}
+	return problems
+}
+
+// Lists the details files, relative to the logs dir, that do not belong to any log entry
+func unreferencedDetailsFiles(root string, logs []*protos.BuildLogEntry) ([]string, error) {
+	referenced := map[string]bool{}
+	for _, entry := range logs {
+		referenced[filepath.Clean(LogDetailsPath(root, entry))] = true
+	}
+	monthDirs, _ := os.ReadDir(LogsDir(root))
+	var orphans []string
+	for _, d := range monthDirs {
+		if !d.IsDir() || !logDetailsFilePattern.MatchString(d.Name()) {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(LogsDir(root), d.Name()))
+		if err != nil {
+			return orphans, err
+		}
+		for _, f := range files {
+			path := filepath.Join(LogsDir(root), d.Name(), f.Name())
+			if strings.HasSuffix(f.Name(), ".md") && !referenced[filepath.Clean(path)] {
+				orphans = append(orphans, d.Name()+"/"+f.Name())
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// Details files named for a log entry on the date, whether by the date alone or by an ID derived from it
+func detailsFilesOfDate(files []string, date string) []string {
+	var matches []string
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".md")
+		if name == date || strings.HasPrefix(name, date+"-") {
+			matches = append(matches, f)
+		}
+	}
+	return matches
+}
+
+// Returns the line numbers, starting from 1, of each log entry and each of its work periods in the text of a log
+// file as written by WriteLogs
+func LogEntryLines(text string) (entries []int, periods [][]int) {
+	for i, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "log_entry:") || strings.HasPrefix(line, "log_entry {") {
+			entries = append(entries, i+1)
+			periods = append(periods, nil)
+		} else if len(entries) > 0 && strings.HasPrefix(strings.TrimSpace(line), "work_period") {
+			periods[len(periods)-1] = append(periods[len(periods)-1], i+1)
+		}
+	}
+	return entries, periods
+}
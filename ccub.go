@@ -1,10 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/cragcraig/ccub/buildlog"
 	"github.com/cragcraig/ccub/cli"
 	"github.com/cragcraig/ccub/cmds"
 )
@@ -12,23 +15,66 @@ import (
 const cliName = "ccub"
 
 var commands = map[string]cli.Command{
-	"log":    cmds.LogCmd,
-	"start":  cmds.StartCmd,
-	"stop":   cmds.StopCmd,
-	"status": cmds.StatusCmd,
-	"edit":   cmds.EditCmd,
-	"render": cmds.RenderCmd,
+	"log":          cmds.LogCmd,
+	"start":        cmds.StartCmd,
+	"stop":         cmds.StopCmd,
+	"status":       cmds.StatusCmd,
+	"edit":         cmds.EditCmd,
+	"render":       cmds.RenderCmd,
+	"builderhours": cmds.BuilderHoursCmd,
+	"report":       cmds.ReportCmd,
+	"migrate":      cmds.MigrateCmd,
+	"assemblies":   cmds.AssembliesCmd,
+	"init":         cmds.InitCmd,
+	"restore":      cmds.RestoreCmd,
+	"validate":     cmds.ValidateCmd,
+	"search":       cmds.SearchCmd,
+	"site":         cmds.SiteCmd,
+	"attach":       cmds.AttachCmd,
+	"pause":        cmds.PauseCmd,
+	"resume":       cmds.ResumeCmd,
+	"amend":        cmds.AmendCmd,
+	"history":      cmds.HistoryCmd,
+	"undo":         cmds.UndoCmd,
+	"sync":         cmds.SyncCmd,
+	"import":       cmds.ImportCmd,
+	"export":       cmds.ExportCmd,
+	"convert":      cmds.ConvertCmd,
 }
 
 func main() {
 	var cmdName string
 	var args []string
 
-	if len(os.Args) > 1 {
-		cmdName = os.Args[1]
-		args = os.Args[2:]
+	// Global flags precede the command name
+	globals := flag.NewFlagSet(cliName, flag.ContinueOnError)
+	projectDir := globals.String("C", "", "Project directory; defaults to $"+buildlog.ProjectEnvVar+" or the nearest parent directory containing a project")
+	globals.StringVar(projectDir, "project", "", "Alias of -C")
+	asJSON := globals.Bool("json", false, "Print the result of the command as JSON")
+	if err := globals.Parse(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Println(err)
+		}
+		return
 	}
-	if err := cli.Exec(commands, cliName, strings.ToLower(cmdName), args); err != nil {
-		fmt.Println(err)
+	cmds.SetProjectDir(*projectDir)
+
+	if globals.NArg() > 0 {
+		cmdName = globals.Arg(0)
+		args = globals.Args()[1:]
+	}
+	// Errors are reported on stderr given -json, such that stdout consists only of the JSON
+	errOut := os.Stdout
+	if *asJSON {
+		errOut = os.Stderr
+	}
+	buildlog.SetJournalCommand(append([]string{cliName}, globals.Args()...))
+	if err := cli.Exec(commands, cliName, strings.ToLower(cmdName), args, *asJSON); err != nil {
+		fmt.Fprintln(errOut, err)
+		os.Exit(1)
+	}
+	if err := buildlog.CommitPendingChanges(); err != nil {
+		fmt.Fprintln(errOut, err)
+		os.Exit(1)
 	}
 }
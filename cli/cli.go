@@ -4,9 +4,13 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"os"
 )
 
-const helpCmdName = "help"
+const (
+	helpCmdName = "help"
+)
 
 type CommandMetadata struct {
 	Description string
@@ -14,10 +18,10 @@ type CommandMetadata struct {
 
 type Command interface {
 	Metadata() CommandMetadata
-	ParseArgsAndExecute(name string, argv []string) error
+	ParseArgsAndExecute(name string, argv []string) (Result, error)
 }
 
-func ConstructCommand[T any](metadata CommandMetadata, parseArgs func(name string, args []string) (T, error), execute func(args T) error) Command {
+func ConstructCommand[T any](metadata CommandMetadata, parseArgs func(name string, args []string) (T, error), execute func(args T) (Result, error)) Command {
 	return commandTmpl[T]{
 		metadata:  metadata,
 		parseArgs: parseArgs,
@@ -28,25 +32,28 @@ func ConstructCommand[T any](metadata CommandMetadata, parseArgs func(name strin
 type commandTmpl[T any] struct {
 	metadata  CommandMetadata
 	parseArgs func(name string, argv []string) (T, error)
-	execute   func(args T) error
+	execute   func(args T) (Result, error)
 }
 
 func (cmd commandTmpl[T]) Metadata() CommandMetadata {
 	return cmd.metadata
 }
 
-func (cmd commandTmpl[T]) ParseArgsAndExecute(name string, argv []string) error {
+func (cmd commandTmpl[T]) ParseArgsAndExecute(name string, argv []string) (Result, error) {
 	if args, err := cmd.parseArgs(name, argv); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
-			return nil
+			return nil, nil
 		}
-		return err
+		if len(ErrorCode(err)) == 0 {
+			err = NewError(ErrorCodeUsage, err)
+		}
+		return nil, err
 	} else {
 		return cmd.execute(args)
 	}
 }
 
-func Exec(commands map[string]Command, cliName string, cmdName string, argv []string) error {
+func execCommand(commands map[string]Command, cliName string, cmdName string, argv []string) (Result, error) {
 	if cmdName == helpCmdName || cmdName == "" {
 		// Help
 		return help(commands, cliName, argv)
@@ -55,40 +62,86 @@ func Exec(commands map[string]Command, cliName string, cmdName string, argv []st
 		return cmd.ParseArgsAndExecute(cmdName, argv)
 	} else {
 		// Unrecognized
-		return fmt.Errorf("Unrecognized command \"%s\", try \"help\"", cmdName)
+		return nil, NewError(ErrorCodeUnknownCommand, fmt.Errorf("Unrecognized command \"%s\", try \"help\"", cmdName))
 	}
 }
 
-func printVersion() {
-	fmt.Println("Carbon Cub Build Log, version 0.20")
+// Executes a command, printing its result as text or, given the global -json flag, as JSON. A result is printed even
+// if the command also returns an error, which the caller is expected to report.
+func Exec(commands map[string]Command, cliName string, cmdName string, argv []string, asJSON bool) error {
+	if asJSON {
+		// Anything else printed by the command, such as prompts, goes to stderr such that stdout consists only of the
+		// JSON
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+		result, err := execCommand(commands, cliName, cmdName, argv)
+		if writeErr := writeJSON(stdout, cmdName, result, err); writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+	result, err := execCommand(commands, cliName, cmdName, argv)
+	if result != nil {
+		if printErr := result.PrintText(os.Stdout); printErr != nil {
+			return printErr
+		}
+		if interactive, ok := result.(InteractiveResult); ok && err == nil {
+			return interactive.Interact()
+		}
+	}
+	return err
 }
 
-func help(commands map[string]Command, cliName string, argv []string) error {
-	if len(argv) == 0 {
-		printVersion()
-		fmt.Println("")
-		fmt.Printf("Usage:  %s COMMAND [-flag1 value] [-flag2 value] ...\n", cliName)
-		fmt.Printf(" e.g.,  %s log -help\n", cliName)
-		fmt.Printf("        %s log -assembly \"left wing\" -date today -time 1pm-3:15pm\n", cliName)
-		fmt.Println("")
-		fmt.Println("Commands:")
-		// Get length of the longest command
-		max := 0
-		for k, _ := range commands {
-			if l := len(k); l > max {
-				max = l
-			}
+func printVersion(w io.Writer) {
+	fmt.Fprintln(w, "Carbon Cub Build Log, version 0.20")
+}
+
+type commandInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type helpResult struct {
+	cliName  string
+	Commands []commandInfo `json:"commands"`
+}
+
+func (r *helpResult) PrintText(w io.Writer) error {
+	printVersion(w)
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Usage:  %s [-C project_dir] [-json] COMMAND [-flag1 value] [-flag2 value] ...\n", r.cliName)
+	fmt.Fprintf(w, " e.g.,  %s log -help\n", r.cliName)
+	fmt.Fprintf(w, "        %s log -assembly \"left wing\" -date today -time 1pm-3:15pm\n", r.cliName)
+	fmt.Fprintf(w, "        %s -json status\n", r.cliName)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Commands:")
+	// Get length of the longest command
+	max := 0
+	for _, cmd := range r.Commands {
+		if l := len(cmd.Name); l > max {
+			max = l
 		}
-		// Print all commands with descriptions
+	}
+	// Print all commands with descriptions
+	for _, cmd := range r.Commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", max, cmd.Name, cmd.Description)
+	}
+	return nil
+}
+
+func help(commands map[string]Command, cliName string, argv []string) (Result, error) {
+	if len(argv) == 0 {
+		result := &helpResult{cliName: cliName}
 		for name, cmd := range commands {
-			fmt.Printf("  %-*s  %s\n", max, name, cmd.Metadata().Description)
+			result.Commands = append(result.Commands, commandInfo{name, cmd.Metadata().Description})
 		}
-		return nil
+		return result, nil
 	} else {
 		cmdName := argv[0]
 		if _, exists := commands[cmdName]; exists {
-			return fmt.Errorf("Try '%s -help'", cmdName)
+			return nil, NewError(ErrorCodeUsage, fmt.Errorf("Try '%s -help'", cmdName))
 		}
-		return errors.New(fmt.Sprintf("Unable: '%s' is not a supported command", cmdName))
+		return nil, NewError(ErrorCodeUnknownCommand, fmt.Errorf("Unable: '%s' is not a supported command", cmdName))
 	}
 }
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type echoResult struct {
+	Title string   `json:"title"`
+	Args  []string `json:"args"`
+}
+
+func (r *echoResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Title: %s\n", r.Title)
+	return err
+}
+
+// Prompts on stdout, as commands do, and echoes its -title flag and remaining args
+var echoCmd = ConstructCommand(
+	CommandMetadata{
+		Description: "Echo the title",
+	},
+	func(name string, argv []string) (*echoResult, error) {
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		title := flags.String("title", "", "Title")
+		if err := flags.Parse(argv); err != nil {
+			return nil, err
+		}
+		return &echoResult{Title: *title, Args: flags.Args()}, nil
+	},
+	func(r *echoResult) (Result, error) {
+		fmt.Println("Prompt")
+		if len(r.Title) == 0 {
+			return r, NewError("no_title", errors.New("No title"))
+		}
+		return r, nil
+	})
+
+// Executes a command with stdout and stderr captured, returning what was written to each
+func execCaptured(t *testing.T, argv []string, asJSON bool) (stdout string, stderr string, err error) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, outErr := os.Create(filepath.Join(dir, "stdout"))
+	if outErr != nil {
+		t.Fatal(outErr)
+	}
+	defer outFile.Close()
+	errFile, errErr := os.Create(filepath.Join(dir, "stderr"))
+	if errErr != nil {
+		t.Fatal(errErr)
+	}
+	defer errFile.Close()
+	prevStdout, prevStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	err = Exec(map[string]Command{"echo": echoCmd}, "ccub", "echo", argv, asJSON)
+	restored := os.Stdout == outFile
+	os.Stdout, os.Stderr = prevStdout, prevStderr
+	if !restored {
+		t.Error("Stdout was not restored after executing the command")
+	}
+
+	outText, readErr := os.ReadFile(outFile.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	errText, readErr := os.ReadFile(errFile.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return string(outText), string(errText), err
+}
+
+func TestExecJSON(t *testing.T) {
+	stdout, stderr, err := execCaptured(t, []string{"-title", "Rivet longerons", "extra"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Command string      `json:"command"`
+		OK      bool        `json:"ok"`
+		Result  *echoResult `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("Stdout is not JSON: %s\n%s", err.Error(), stdout)
+	}
+	if out.Command != "echo" || !out.OK || out.Result == nil || out.Result.Title != "Rivet longerons" {
+		t.Fatalf("JSON result is %+v, expected echo of the title", out)
+	}
+	if !reflect.DeepEqual(out.Result.Args, []string{"extra"}) {
+		t.Errorf("Command args are %v, expected [extra]", out.Result.Args)
+	}
+	if stderr != "Prompt\n" {
+		t.Errorf("Stderr is %q, expected the prompt", stderr)
+	}
+
+	// Errors are included in the JSON, with the result
+	stdout, _, err = execCaptured(t, nil, true)
+	if ErrorCode(err) != "no_title" {
+		t.Errorf("Error is %v, expected no_title", err)
+	}
+	var failed struct {
+		OK    bool `json:"ok"`
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &failed); err != nil {
+		t.Fatalf("Stdout is not JSON: %s\n%s", err.Error(), stdout)
+	}
+	if failed.OK || failed.Error.Code != "no_title" {
+		t.Errorf("JSON is %s, expected the no_title error", stdout)
+	}
+}
+
+func TestExecText(t *testing.T) {
+	// -json following the command name is the command's own
+	stdout, stderr, err := execCaptured(t, []string{"-title", "-json", "extra"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout != "Prompt\nTitle: -json\n" {
+		t.Errorf("Stdout is %q, expected the prompt and the title -json", stdout)
+	}
+	if len(stderr) > 0 {
+		t.Errorf("Stderr is %q, expected nothing", stderr)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Codes of errors common to all commands
+const (
+	ErrorCodeUsage          = "usage"
+	ErrorCodeUnknownCommand = "unknown_command"
+	ErrorCodeFailed         = "failed"
+)
+
+// Structured result of a command, printed as text or, given the -json flag, encoded as JSON
+type Result interface {
+	PrintText(w io.Writer) error
+}
+
+// Implemented by results that continue interactively once printed as text, e.g., by launching an editor. Skipped when
+// printing JSON.
+type InteractiveResult interface {
+	Result
+	Interact() error
+}
+
+// An error with a machine-readable code, e.g., "not_found"
+type Error struct {
+	Code string
+	Err  error
+}
+
+func NewError(code string, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Returns the code of err, or an empty string if it has none
+func ErrorCode(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ""
+}
+
+type jsonError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonOutput struct {
+	Command string     `json:"command"`
+	OK      bool       `json:"ok"`
+	Result  Result     `json:"result,omitempty"`
+	Error   *jsonError `json:"error,omitempty"`
+}
+
+func writeJSON(w io.Writer, cmdName string, result Result, err error) error {
+	out := jsonOutput{Command: cmdName, OK: err == nil, Result: result}
+	if err != nil {
+		out.Error = &jsonError{Code: ErrorCode(err), Message: err.Error()}
+		if len(out.Error.Code) == 0 {
+			out.Error.Code = ErrorCodeFailed
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,295 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var AmendCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Change the work periods or metadata of an existing log entry",
+	},
+	parseAmend,
+	executeAmend)
+
+// Usage of each amend operation, in the order listed by help
+var amendOperations = [][2]string{
+	{"show", "List the log entry and its numbered work periods"},
+	{"add-period TIME", "Add work period(s), e.g., 1pm-3:15pm"},
+	{"set-period N TIME", "Change the start and end of work period N, e.g., 2 1pm-3:30pm"},
+	{"remove-period N", "Remove work period N"},
+	{"builder N NAME", "Attribute work period N to a builder; empty to clear"},
+	{"helpers N LIST", "Replace the helpers of work period N with a comma-separated list; empty to clear"},
+	{"assembly NAME", "Change the top-level assembly"},
+	{"subassembly LIST", "Replace the subassemblies with a comma-separated list; empty to clear"},
+	{"title TEXT", "Change the title"},
+	{"tags LIST", "Replace the tags with a comma-separated list; empty to clear"},
+	{"date DATE", "Move the log entry, along with its details file and attachments, to another date"},
+}
+
+type amendArgs struct {
+	root      string
+	selector  *entrySelector
+	operation string
+	operands  []string
+	config    *protos.ProjectConfig
+}
+
+func parseAmend(name string, argv []string) (*amendArgs, error) {
+	args := &amendArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s: %s [flags] OPERATION [OPERANDS]\n\nOperations:\n", name, name)
+		for _, op := range amendOperations {
+			fmt.Fprintf(flags.Output(), "  %-18s  %s\n", op[0], op[1])
+		}
+		fmt.Fprintf(flags.Output(), "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	// Raw flags
+	selector := defineEntrySelectorFlags(flags)
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Log entry
+	if sel, err := selector.parse(root); err != nil {
+		return nil, err
+	} else {
+		args.selector = sel
+	}
+	// Operation
+	args.operation = "show"
+	if flags.NArg() > 0 {
+		args.operation = flags.Arg(0)
+		args.operands = flags.Args()[1:]
+	}
+	expected := -1
+	for _, op := range amendOperations {
+		if fields := strings.Fields(op[0]); fields[0] == args.operation {
+			expected = len(fields) - 1
+		}
+	}
+	if expected < 0 {
+		flags.Usage()
+		return nil, fmt.Errorf("Unrecognized operation \"%s\"", args.operation)
+	}
+	if len(args.operands) != expected {
+		return nil, fmt.Errorf("Operation '%s' expects %d operand(s), got %d", args.operation, expected, len(args.operands))
+	}
+	if args.config, err = buildlog.ReadProjectConfig(root); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+func parsePeriodNumber(entry *protos.BuildLogEntry, arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(entry.WorkPeriod) {
+		return -1, fmt.Errorf("Work period must be a number from 1 to %d, see 'amend show'", len(entry.WorkPeriod))
+	}
+	return n - 1, nil
+}
+
+func parseCommaList(arg string, what string) ([]string, error) {
+	if len(arg) == 0 {
+		return nil, nil
+	}
+	var items []string
+	for _, v := range strings.Split(arg, ",") {
+		if len(strings.TrimSpace(v)) == 0 {
+			return nil, fmt.Errorf("%s must not be empty strings", what)
+		}
+		items = append(items, strings.TrimSpace(v))
+	}
+	return items, nil
+}
+
+type amendResult struct {
+	Operation string       `json:"operation"`
+	Entry     *entryResult `json:"entry"`
+	// Unset if the log entry was only shown
+	LogsFile string `json:"logs_file,omitempty"`
+}
+
+func (r *amendResult) PrintText(w io.Writer) error {
+	entry := r.Entry
+	fmt.Fprintf(w, "%s  %s (%s)\n", entry.ID, entry.Title, entry.Assembly)
+	if len(entry.Subassemblies) > 0 {
+		fmt.Fprintf(w, "  Subassemblies:  %s\n", strings.Join(entry.Subassemblies, ", "))
+	}
+	if len(entry.Tags) > 0 {
+		fmt.Fprintf(w, "  Tags:  %s\n", strings.Join(entry.Tags, ", "))
+	}
+	for i, wp := range entry.Periods {
+		if wp.Ongoing {
+			fmt.Fprintf(w, "  %d.  %s-  (ongoing)", i+1, wp.StartTime)
+		} else {
+			fmt.Fprintf(w, "  %d.  %s-%s  (%s)", i+1, wp.StartTime, wp.EndTime, durationMinToString(wp.Minutes))
+		}
+		if len(wp.Builder) > 0 {
+			fmt.Fprintf(w, "  %s", wp.Builder)
+		}
+		if len(wp.Helpers) > 0 {
+			fmt.Fprintf(w, "  with %s", strings.Join(wp.Helpers, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "  Total:  %s\n", durationMinToString(entry.Minutes))
+	if len(r.LogsFile) > 0 {
+		fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	}
+	return nil
+}
+
+// Applies an amend operation to the log entry at logs[index]
+func amendLogEntry(args *amendArgs, logs []*protos.BuildLogEntry, index int) error {
+	entry := logs[index]
+	ops := args.operands
+	switch args.operation {
+	case "add-period":
+		periods, err := buildlog.ParseWorkPeriodsArgForLog(entry, ops[0])
+		if err != nil {
+			return err
+		}
+		entry.WorkPeriod = append(entry.WorkPeriod, periods...)
+	case "set-period":
+		n, err := parsePeriodNumber(entry, ops[0])
+		if err != nil {
+			return err
+		}
+		periods, err := buildlog.ParseWorkPeriodsArgForLog(entry, ops[1])
+		if err != nil {
+			return err
+		}
+		if len(periods) != 1 {
+			return errors.New("Exactly one work period is required, e.g., 1pm-3:30pm")
+		}
+		return buildlog.ReplaceWorkPeriod(entry, n, periods[0])
+	case "remove-period":
+		n, err := parsePeriodNumber(entry, ops[0])
+		if err != nil {
+			return err
+		}
+		return buildlog.RemoveWorkPeriod(entry, n)
+	case "builder":
+		n, err := parsePeriodNumber(entry, ops[0])
+		if err != nil {
+			return err
+		}
+		if len(ops[1]) == 0 {
+			entry.WorkPeriod[n].Builder = ""
+			return nil
+		}
+		b, err := buildlog.ParseBuilderArg(args.config, ops[1])
+		if err != nil {
+			return err
+		}
+		entry.WorkPeriod[n].Builder = b
+	case "helpers":
+		n, err := parsePeriodNumber(entry, ops[0])
+		if err != nil {
+			return err
+		}
+		if len(ops[1]) == 0 {
+			entry.WorkPeriod[n].Helper = nil
+			return nil
+		}
+		h, err := buildlog.ParseHelpersArg(args.config, ops[1])
+		if err != nil {
+			return err
+		}
+		entry.WorkPeriod[n].Helper = h
+	case "assembly":
+		a, err := buildlog.ParseAssemblyArg(args.config, ops[0])
+		if err != nil {
+			return err
+		}
+		entry.Assembly = a
+	case "subassembly":
+		if len(ops[0]) == 0 {
+			entry.Subassembly = nil
+			return nil
+		}
+		s, err := buildlog.ParseSubassemblyArg(args.config, entry.Assembly, ops[0])
+		if err != nil {
+			return err
+		}
+		entry.Subassembly = s
+	case "title":
+		if len(ops[0]) == 0 {
+			return errors.New("Title must not be empty")
+		}
+		for _, r := range ops[0] {
+			if !unicode.IsPrint(r) {
+				return errors.New("Title must be a single line of text (no newlines)")
+			}
+		}
+		entry.Title = ops[0]
+	case "tags":
+		tags, err := parseCommaList(ops[0], "Tags")
+		if err != nil {
+			return err
+		}
+		entry.Tags = tags
+	case "date":
+		date, err := buildlog.ParseDateArg(ops[0])
+		if err != nil {
+			return err
+		}
+		if buildlog.FormatDateForLog(date) == entry.Date {
+			return fmt.Errorf("Log entry %s is already on %s", buildlog.LogEntryID(entry), entry.Date)
+		}
+		return buildlog.MoveLogEntry(args.root, logs, index, date)
+	}
+	return nil
+}
+
+func AmendLogUpdater(args *amendArgs, result *amendResult) buildlog.LogUpdater {
+	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		index, err := args.selector.find(logs)
+		if err != nil {
+			return nil, err
+		}
+		if err := amendLogEntry(args, logs, index); err != nil {
+			return nil, err
+		}
+		result.Entry = newEntryResult(args.root, logs[index])
+		return logs, nil
+	}
+}
+
+func executeAmend(args *amendArgs) (cli.Result, error) {
+	result := &amendResult{Operation: args.operation}
+	if args.operation == "show" {
+		logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+		if err != nil {
+			return nil, err
+		}
+		index, err := args.selector.find(logs.LogEntry)
+		if err != nil {
+			return nil, err
+		}
+		result.Entry = newEntryResult(args.root, logs.LogEntry[index])
+		return result, nil
+	}
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(args.root), AmendLogUpdater(args, result)); err != nil {
+		return nil, err
+	}
+	result.LogsFile = buildlog.LogsPath(args.root)
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package cmds
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var AssembliesCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "List assemblies and subassemblies with hours worked",
+	},
+	parseAssemblies,
+	executeAssemblies)
+
+func parseAssemblies(name string, argv []string) (*any, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func withAliases(name string, aliases []string) string {
+	if len(aliases) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(aliases, ", "))
+}
+
+type subassemblyHours struct {
+	Name       string   `json:"name"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Minutes    int      `json:"minutes"`
+	Configured bool     `json:"configured"`
+}
+
+type assemblyHours struct {
+	subassemblyHours
+	Subassemblies []subassemblyHours `json:"subassemblies"`
+}
+
+type assembliesResult struct {
+	Assemblies []assemblyHours `json:"assemblies"`
+}
+
+func (r *assembliesResult) PrintText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, a := range r.Assemblies {
+		note := ""
+		if !a.Configured {
+			note = " (not configured)"
+		}
+		fmt.Fprintf(tw, "%s%s\t%sh\n", withAliases(a.Name, a.Aliases), note, buildlog.FormatHours(a.Minutes))
+		for _, sa := range a.Subassemblies {
+			if sa.Configured {
+				fmt.Fprintf(tw, "  %s\t%sh\n", withAliases(sa.Name, sa.Aliases), buildlog.FormatHours(sa.Minutes))
+			} else {
+				fmt.Fprintf(tw, "  %s (not configured)\t%sh\n", sa.Name, buildlog.FormatHours(sa.Minutes))
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func executeAssemblies(_ *any) (cli.Result, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	config, err := buildlog.ReadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(root))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Sum minutes per assembly and per subassembly
+	assemblyMinutes := map[string]int{}
+	subassemblyMinutes := map[string]map[string]int{}
+	var unlisted []*protos.Assembly
+	for _, entry := range logs.LogEntry {
+		minutes := buildlog.LogEntryMinutes(entry)
+		assemblyMinutes[entry.Assembly] += minutes
+		if _, exists := subassemblyMinutes[entry.Assembly]; !exists {
+			subassemblyMinutes[entry.Assembly] = map[string]int{}
+		}
+		for _, sa := range entry.Subassembly {
+			subassemblyMinutes[entry.Assembly][sa] += minutes
+		}
+		if buildlog.FindAssembly(config, entry.Assembly) == nil && buildlog.FindAssembly(&protos.ProjectConfig{Assembly: unlisted}, entry.Assembly) == nil {
+			unlisted = append(unlisted, &protos.Assembly{Name: entry.Assembly})
+		}
+	}
+
+	result := &assembliesResult{Assemblies: []assemblyHours{}}
+	addAssembly := func(a *protos.Assembly, configured bool) {
+		hours := assemblyHours{
+			subassemblyHours: subassemblyHours{Name: a.Name, Aliases: a.Alias, Minutes: assemblyMinutes[a.Name], Configured: configured},
+			Subassemblies:    []subassemblyHours{},
+		}
+		listed := map[string]bool{}
+		for _, sa := range a.Subassembly {
+			listed[sa.Name] = true
+			hours.Subassemblies = append(hours.Subassemblies, subassemblyHours{sa.Name, sa.Alias, subassemblyMinutes[a.Name][sa.Name], true})
+		}
+		// Subassemblies missing from the config follow those listed, alphabetically
+		var unlistedSubassemblies []string
+		for sa := range subassemblyMinutes[a.Name] {
+			if !listed[sa] {
+				unlistedSubassemblies = append(unlistedSubassemblies, sa)
+			}
+		}
+		sort.Strings(unlistedSubassemblies)
+		for _, sa := range unlistedSubassemblies {
+			hours.Subassemblies = append(hours.Subassemblies, subassemblyHours{Name: sa, Minutes: subassemblyMinutes[a.Name][sa]})
+		}
+		result.Assemblies = append(result.Assemblies, hours)
+	}
+	for _, a := range config.Assembly {
+		addAssembly(a, true)
+	}
+	for _, a := range unlisted {
+		addAssembly(a, false)
+	}
+	return result, nil
+}
@@ -0,0 +1,217 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var AttachCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Attach photos or other files to a log entry",
+	},
+	parseAttach,
+	executeAttach)
+
+type attachArgs struct {
+	root    string
+	files   []string
+	caption string
+	// Nil if the log entry should be suggested from the capture time of each photo
+	selector *entrySelector
+	loc      *time.Location
+	yes      bool
+}
+
+func parseAttach(name string, argv []string) (*attachArgs, error) {
+	args := &attachArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s: %s [flags] FILE...\n\n", name, name)
+		fmt.Fprintf(flags.Output(), "Without 'id', 'date' or 'assembly', photos are attached to the log entry with a work period in progress when they were taken.\n\n")
+		flags.PrintDefaults()
+	}
+	// Raw flags
+	selFlags := defineEntrySelectorFlags(flags)
+	caption := flags.String("caption", "", "Single line caption for the attached files")
+	zone := flags.String("zone", buildlog.LocalTimeZone(), "Time zone in which photos that do not record a UTC offset were taken")
+	yes := flags.Bool("y", false, "Attach photos to the suggested log entry without asking for confirmation")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Files
+	if flags.NArg() == 0 {
+		return nil, errors.New("At least one file to attach is required")
+	}
+	args.files = flags.Args()
+	// Log entry
+	explicit := false
+	flags.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "id" || f.Name == "date" || f.Name == "assembly"
+	})
+	if explicit {
+		if args.selector, err = selFlags.parse(root); err != nil {
+			return nil, err
+		}
+	}
+	// Zone
+	if args.loc, err = buildlog.LoadTimeZone(*zone); err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(*caption, "\n") {
+		return nil, errors.New("'caption' must be a single line")
+	}
+	args.caption = *caption
+	args.yes = *yes
+	return args, nil
+}
+
+// Returns the index of the log entry to attach a file to, suggesting the log entry with a work period in progress
+// when a photo was taken unless one was selected
+func chooseAttachmentEntry(logs []*protos.BuildLogEntry, f string, captured time.Time, hasCaptured bool, args *attachArgs) (int, error) {
+	if args.selector != nil {
+		return args.selector.find(logs)
+	}
+	if !hasCaptured {
+		return -1, fmt.Errorf("%s does not record when it was taken, specify 'id' or 'date'", f)
+	}
+	indices := buildlog.FindLogEntriesAt(logs, captured, time.Now())
+	if len(indices) == 0 {
+		return -1, fmt.Errorf("No work period was in progress when %s was taken at %s, specify 'id' or 'date'", f, captured.Format(time.RFC1123))
+	}
+	for _, i := range indices {
+		entry := logs[i]
+		if args.yes || confirm(fmt.Sprintf("Attach %s, taken %s, to %s: %s (%s)?", filepath.Base(f), captured.Format(time.Kitchen), buildlog.LogEntryID(entry), entry.Title, entry.Assembly)) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("Not attaching %s, specify 'id' or 'date'", f)
+}
+
+type attachedFile struct {
+	Source   string `json:"source"`
+	Entry    string `json:"entry"`
+	Assembly string `json:"assembly"`
+	File     string `json:"file"`
+	// Whether the file was already attached to the log entry
+	Existing bool `json:"existing,omitempty"`
+}
+
+type attachResult struct {
+	Attached []attachedFile `json:"attached"`
+	LogsFile string         `json:"logs_file"`
+	// Files copied into the logs dir, removed if the logs are not updated
+	copied []string
+}
+
+func (r *attachResult) PrintText(w io.Writer) error {
+	for _, a := range r.Attached {
+		if a.Existing {
+			fmt.Fprintf(w, "%s is already attached to %s as %s\n", a.Source, a.Entry, a.File)
+		} else {
+			fmt.Fprintf(w, "Attached %s to %s (%s):  %s\n", a.Source, a.Entry, a.Assembly, a.File)
+		}
+	}
+	_, err := fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	return err
+}
+
+// A file to attach and the log entry chosen for it, which is chosen before the logs are locked since it may take
+// confirmation
+type attachment struct {
+	file        string
+	sum         string
+	captured    time.Time
+	hasCaptured bool
+	entryID     string
+}
+
+func chooseAttachments(args *attachArgs) ([]*attachment, error) {
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	buildlog.AssignLogEntryIDs(logs.LogEntry)
+	var attachments []*attachment
+	for _, f := range args.files {
+		a := &attachment{file: f}
+		if a.sum, err = buildlog.FileSHA256(f); err != nil {
+			return nil, err
+		}
+		if a.captured, a.hasCaptured, err = buildlog.ReadCaptureTime(f, args.loc); err != nil {
+			return nil, err
+		}
+		index, err := chooseAttachmentEntry(logs.LogEntry, f, a.captured, a.hasCaptured, args)
+		if err != nil {
+			return nil, err
+		}
+		a.entryID = buildlog.LogEntryID(logs.LogEntry[index])
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+func AttachLogUpdater(args *attachArgs, attachments []*attachment, result *attachResult) buildlog.LogUpdater {
+	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		for _, att := range attachments {
+			f, sum := att.file, att.sum
+			exists, index := buildlog.FindLogEntryByID(logs, att.entryID)
+			if !exists {
+				return nil, fmt.Errorf("Log entry %s was changed while attaching %s, run 'attach' again", att.entryID, f)
+			}
+			entry := logs[index]
+			if exists, i := buildlog.FindAttachment(entry, sum); exists {
+				result.Attached = append(result.Attached, attachedFile{f, buildlog.LogEntryID(entry), entry.Assembly, entry.Attachment[i].File, true})
+				continue
+			}
+			rel, copied, err := buildlog.CopyAttachment(args.root, entry, f, sum)
+			if err != nil {
+				return nil, err
+			}
+			a := &protos.Attachment{
+				File:    rel,
+				Sha256:  sum,
+				Caption: args.caption,
+			}
+			if att.hasCaptured {
+				a.Captured = att.captured.Format(time.RFC3339)
+			}
+			entry.Attachment = append(entry.Attachment, a)
+			if copied {
+				result.copied = append(result.copied, buildlog.AttachmentPath(args.root, a))
+			}
+			result.Attached = append(result.Attached, attachedFile{f, buildlog.LogEntryID(entry), entry.Assembly, buildlog.AttachmentPath(args.root, a), false})
+		}
+		return logs, nil
+	}
+}
+
+func executeAttach(args *attachArgs) (cli.Result, error) {
+	attachments, err := chooseAttachments(args)
+	if err != nil {
+		return nil, err
+	}
+	result := &attachResult{LogsFile: buildlog.LogsPath(args.root)}
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(args.root), AttachLogUpdater(args, attachments, result)); err != nil {
+		for _, f := range result.copied {
+			os.Remove(f)
+		}
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package cmds
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var BuilderHoursCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Export builder hours summary for airworthiness inspection",
+	},
+	parseBuilderHours,
+	executeBuilderHours)
+
+type builderHoursArgs struct {
+	format   string
+	tmplFile string
+	aircraft string
+	builder  string
+	outFile  string
+}
+
+func parseBuilderHours(name string, argv []string) (*builderHoursArgs, error) {
+	args := &builderHoursArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	format := flags.String("format", "md", "Output format, one of: "+strings.Join(buildlog.ValidBuilderHoursFormats(), ", "))
+	tmplFile := flags.String("tmpl", "", "Template text file to use in place of the built-in template for the format; escapes its output as HTML for format html")
+	aircraft := flags.String("aircraft", "", "Aircraft make, model and registration for the cover page")
+	builder := flags.String("builder", "", "Builder for the cover page and signature block, e.g., the applicant for a repairman certificate; defaults to $"+buildlog.BuilderEnvVar+", else the default_builder of the project")
+	outFile := flags.String("o", "", "Output file; defaults to stdout")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	// Format
+	if !containsString(buildlog.ValidBuilderHoursFormats(), *format) {
+		return nil, fmt.Errorf("Format must be one of:\n  %s", strings.Join(buildlog.ValidBuilderHoursFormats(), "\n  "))
+	}
+	args.format = *format
+	args.tmplFile = *tmplFile
+	args.aircraft = *aircraft
+	args.builder = *builder
+	args.outFile = *outFile
+	return args, nil
+}
+
+type builderHoursResult struct {
+	Format       string                  `json:"format"`
+	TotalMinutes int                     `json:"total_minutes"`
+	Builders     []buildlog.BuilderTotal `json:"builders,omitempty"`
+	// Set if written to a file, otherwise the summary is included as text
+	OutFile string `json:"out_file,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+func (r *builderHoursResult) PrintText(w io.Writer) error {
+	if len(r.OutFile) > 0 {
+		_, err := fmt.Fprintf(w, "Builder hours summary (%s hours):  %s\n", buildlog.FormatHours(r.TotalMinutes), r.OutFile)
+		return err
+	}
+	_, err := io.WriteString(w, r.Summary)
+	return err
+}
+
+func executeBuilderHours(args *builderHoursArgs) (cli.Result, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	var tmpl buildlog.ExecutableTemplate
+	if len(args.tmplFile) > 0 {
+		tmpl, err = buildlog.LoadBuilderHoursTemplateFile(args.format, args.tmplFile)
+	} else {
+		tmpl, err = buildlog.LoadBuilderHoursTemplate(args.format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	config, err := buildlog.ReadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	builder := args.builder
+	if len(builder) > 0 {
+		builder, err = buildlog.ParseBuilderArg(config, builder)
+	} else {
+		builder, err = buildlog.DefaultBuilder(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(root))
+	if err != nil {
+		return nil, err
+	}
+	summary, err := buildlog.NewBuilderHoursSummary(root, config, logs.LogEntry, args.aircraft, builder)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &builderHoursResult{Format: args.format, TotalMinutes: summary.TotalMinutes, Builders: summary.Builders, OutFile: args.outFile}
+	if len(args.outFile) == 0 {
+		var sb strings.Builder
+		if err := buildlog.WriteBuilderHoursSummary(&sb, tmpl, summary); err != nil {
+			return nil, err
+		}
+		result.Summary = sb.String()
+		return result, nil
+	}
+	fp, err := os.Create(args.outFile)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	if err := buildlog.WriteBuilderHoursSummary(fp, tmpl, summary); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
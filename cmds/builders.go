@@ -0,0 +1,154 @@
+package cmds
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Attribution of new work periods to a builder, along with any helpers
+type attribution struct {
+	builder string
+	helpers []string
+}
+
+type attributionFlags struct {
+	builder *string
+	helpers *string
+}
+
+func defineAttributionFlags(flags *flag.FlagSet) *attributionFlags {
+	return &attributionFlags{
+		builder: flags.String("builder", "", "Builder who did the work; defaults to $"+buildlog.BuilderEnvVar+", else the default_builder of the project"),
+		helpers: flags.String("helpers", "", "Comma-separated list of others who took part in the work, e.g., students"),
+	}
+}
+
+func (f *attributionFlags) parse(config *protos.ProjectConfig) (*attribution, error) {
+	a := &attribution{}
+	if len(*f.builder) > 0 {
+		b, err := buildlog.ParseBuilderArg(config, *f.builder)
+		if err != nil {
+			return nil, err
+		}
+		a.builder = b
+	} else {
+		b, err := buildlog.DefaultBuilder(config)
+		if err != nil {
+			return nil, err
+		}
+		a.builder = b
+	}
+	if len(*f.helpers) > 0 {
+		h, err := buildlog.ParseHelpersArg(config, *f.helpers)
+		if err != nil {
+			return nil, err
+		}
+		a.helpers = h
+	}
+	return a, nil
+}
+
+func (a *attribution) apply(periods []*protos.TimePeriod) {
+	for _, wp := range periods {
+		buildlog.AttributeWorkPeriod(wp, a.builder, a.helpers)
+	}
+}
+
+// Parses the -builder flag of commands that act on ongoing or paused work, returning the builder named, if any, and
+// the caller, i.e., the builder to whom work is attributed by default
+func parseBuilderSelection(root string, builder string) (named string, caller string, err error) {
+	config, err := buildlog.ReadProjectConfig(root)
+	if err != nil {
+		return "", "", err
+	}
+	if len(builder) > 0 {
+		if named, err = buildlog.ParseBuilderArg(config, builder); err != nil {
+			return "", "", err
+		}
+	}
+	if caller, err = buildlog.DefaultBuilder(config); err != nil {
+		return "", "", err
+	}
+	return named, caller, nil
+}
+
+// Chooses whose work a command acts on among the builders with ongoing or paused work: the builder named, else the
+// caller, else the only one. Work not attributed to anyone is chosen for a named builder without work of their own, so
+// that it may be attributed to them. Returns false, along with the builder named or the caller, if there is no such
+// work.
+func chooseBuilder(active []string, named string, caller string) (string, bool, error) {
+	if len(named) > 0 {
+		if containsString(active, named) {
+			return named, true, nil
+		} else if containsString(active, "") {
+			return "", true, nil
+		}
+		return named, false, nil
+	}
+	if containsString(active, caller) {
+		return caller, true, nil
+	}
+	switch len(active) {
+	case 0:
+		return caller, false, nil
+	case 1:
+		return active[0], true, nil
+	}
+	var names []string
+	for _, b := range active {
+		if len(b) == 0 {
+			b = buildlog.UnattributedKey
+		}
+		names = append(names, b)
+	}
+	return "", false, cli.NewError(errorCodeAmbiguous, fmt.Errorf("Several builders are working (%s), choose one with -builder", strings.Join(names, ", ")))
+}
+
+// Filters builders to those whose ongoing or paused work is on the assembly, unless it is empty
+func buildersWorkingOn(logs []*protos.BuildLogEntry, builders []string, assembly string) []string {
+	if len(assembly) == 0 {
+		return builders
+	}
+	var filtered []string
+	for _, b := range builders {
+		if session := buildlog.CurrentSession(logs, b); len(session) > 0 && logs[session[len(session)-1].EntryIndex].Assembly == assembly {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+// Builders with an open work period
+func workingBuilders(logs []*protos.BuildLogEntry) []string {
+	var working []string
+	for _, b := range buildlog.ActiveBuilders(logs) {
+		if open, _, _ := buildlog.FindOpenWorkPeriod(logs, b); open {
+			working = append(working, b)
+		}
+	}
+	return working
+}
+
+// Builders whose session of work is paused
+func pausedBuilders(logs []*protos.BuildLogEntry) []string {
+	var paused []string
+	for _, b := range buildlog.ActiveBuilders(logs) {
+		if exists, _, _ := buildlog.FindPausedWorkPeriod(logs, b); exists {
+			paused = append(paused, b)
+		}
+	}
+	return paused
+}
+
+// Describes whose work a message is about, e.g., " by Craig", or nothing for work not attributed to anyone
+func byBuilder(builder string) string {
+	if len(builder) == 0 {
+		return ""
+	}
+	return " by " + builder
+}
@@ -0,0 +1,91 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var ConvertCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Change the format in which the logs are stored",
+	},
+	parseConvert,
+	executeConvert)
+
+type convertArgs struct {
+	root string
+	to   buildlog.LogStorage
+}
+
+func parseConvert(name string, argv []string) (*convertArgs, error) {
+	args := &convertArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	to := flags.String("to", "", "Storage to convert the logs to, one of: "+strings.Join(buildlog.LogStorageNames(), ", ")+"; required")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("Unexpected argument \"%s\"", flags.Arg(0))
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Storage
+	if len(*to) == 0 {
+		return nil, errors.New("'to' is required")
+	}
+	if args.to, err = buildlog.FindLogStorage(*to); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+type convertResult struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Entries  int    `json:"entries"`
+	LogsFile string `json:"logs_file"`
+}
+
+func (r *convertResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Converted %d log entries from %s to %s\n\nUpdated log file:   %s\n", r.Entries, r.From, r.To, r.LogsFile)
+	return err
+}
+
+func executeConvert(args *convertArgs) (cli.Result, error) {
+	config, err := buildlog.ReadProjectConfig(args.root)
+	if err != nil {
+		return nil, err
+	}
+	from := buildlog.LogsPath(args.root)
+	result := &convertResult{From: buildlog.ProjectLogStorage(args.root).Name(), To: args.to.Name()}
+	if result.LogsFile, err = buildlog.ConvertLogStorage(args.root, args.to); err != nil {
+		return nil, err
+	}
+	logs, err := buildlog.ReadLogs(result.LogsFile)
+	if err != nil {
+		return nil, err
+	}
+	result.Entries = len(logs.LogEntry)
+	if config.GitAutoCommit {
+		gitignore, err := buildlog.EnsureLogsGitignore(args.root)
+		if err != nil {
+			return nil, err
+		}
+		paths := []string{from, result.LogsFile, buildlog.ConfigPath(args.root), gitignore}
+		if _, err := buildlog.GitCommit(args.root, paths, fmt.Sprintf("Convert logs to %s storage", result.To)); err != nil {
+			return nil, fmt.Errorf("Could not commit the converted logs, commit them with 'sync'\n%s", err.Error())
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package cmds
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var ExportCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Export log entries or work periods to CSV, JSON, iCalendar or a spreadsheet",
+	},
+	parseExport,
+	executeExport)
+
+var validExportRows = []string{"entry", "period"}
+
+type exportArgs struct {
+	root    string
+	format  string
+	periods bool
+	details bool
+	output  string
+	filter  *buildlog.LogFilter
+}
+
+func parseExport(name string, argv []string) (*exportArgs, error) {
+	args := &exportArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	format := flags.String("format", "", "Output format, one of: "+strings.Join(buildlog.ExportFormats(), ", ")+"; defaults to the extension of 'o', else csv")
+	rows := flags.String("rows", "", "One row per log entry or per work period, one of: "+strings.Join(validExportRows, ", ")+"; defaults to period for ics, else entry")
+	details := flags.Bool("details", false, "Include the text of the details file of each log entry")
+	output := flags.String("o", "", "File to write; defaults to stdout")
+	filterFlags := defineLogFilterFlags(flags)
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("Unexpected argument \"%s\"", flags.Arg(0))
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Format
+	args.output = *output
+	args.format = strings.ToLower(*format)
+	if len(args.format) == 0 {
+		args.format = strings.ToLower(strings.TrimPrefix(filepath.Ext(args.output), "."))
+		if !containsString(buildlog.ExportFormats(), args.format) {
+			args.format = "csv"
+		}
+	}
+	if _, err := buildlog.FindExporter(args.format); err != nil {
+		return nil, err
+	}
+	if args.format == "xlsx" && len(args.output) == 0 {
+		return nil, errors.New("Format xlsx requires an output file given by 'o'")
+	}
+	// Rows
+	switch *rows {
+	case "":
+		args.periods = args.format == "ics"
+	case "entry", "period":
+		args.periods = *rows == "period"
+	default:
+		return nil, fmt.Errorf("'rows' must be one of:\n  %s", strings.Join(validExportRows, "\n  "))
+	}
+	args.details = *details
+	// Filter
+	if args.filter, err = filterFlags.parse(root); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+type exportResult struct {
+	data   []byte
+	Format string `json:"format"`
+	Count  int    `json:"count"`
+	// Rows are only included when not written to a file
+	Rows []*buildlog.ExportRow `json:"rows,omitempty"`
+	File string                `json:"file,omitempty"`
+	// Rows of work still ongoing or of no length, left out of calendar events
+	Omitted int `json:"omitted,omitempty"`
+}
+
+func (r *exportResult) PrintText(w io.Writer) error {
+	if len(r.File) == 0 {
+		_, err := w.Write(r.data)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Exported %d rows to %s\n", r.Count, r.File); err != nil {
+		return err
+	}
+	if r.Omitted > 0 {
+		if _, err := fmt.Fprintf(w, "Left out %d rows of work still ongoing or of no length\n", r.Omitted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executeExport(args *exportArgs) (cli.Result, error) {
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil {
+		return nil, err
+	}
+	matches, err := buildlog.FilterLogs(logs.LogEntry, args.filter)
+	if err != nil {
+		return nil, err
+	}
+	export, err := buildlog.NewExport(args.root, matches, args.periods, args.details)
+	if err != nil {
+		return nil, err
+	}
+	omitted := 0
+	if args.format == "ics" {
+		omitted = export.OmitUntimedRows()
+	}
+	exporter, err := buildlog.FindExporter(args.format)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := exporter.Export(&b, export); err != nil {
+		return nil, err
+	}
+	result := &exportResult{data: b.Bytes(), Format: args.format, Count: len(export.Rows), Omitted: omitted}
+	if len(args.output) > 0 {
+		if err := buildlog.WriteFileAtomic(args.output, b.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		result.File = args.output
+	} else {
+		result.Rows = export.Rows
+	}
+	return result, nil
+}
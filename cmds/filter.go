@@ -0,0 +1,106 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+)
+
+type logFilterFlags struct {
+	from         *string
+	to           *string
+	assembly     *string
+	subassembly  *string
+	tags         *string
+	allTags      *bool
+	minDuration  *time.Duration
+	titlePattern *string
+}
+
+// Defines flags selecting a subset of log entries, shared by commands that operate on many log entries
+func defineLogFilterFlags(flags *flag.FlagSet) *logFilterFlags {
+	return &logFilterFlags{
+		from:         flags.String("from", "", "Only include log entries on or after this date. Supported forms: "+strings.Join(buildlog.ValidDateFormats(), ", ")),
+		to:           flags.String("to", "", "Only include log entries on or before this date"),
+		assembly:     flags.String("assembly", "", "Only include log entries for this top-level assembly"),
+		subassembly:  flags.String("subassembly", "", "Only include log entries for any of these comma-separated subassemblies"),
+		tags:         flags.String("tags", "", "Only include log entries with any of these comma-separated tags"),
+		allTags:      flags.Bool("all-tags", false, "Only include log entries with all of the tags given by 'tags'"),
+		minDuration:  flags.Duration("min-duration", 0, "Only include log entries with at least this much work time, e.g., 1h30m"),
+		titlePattern: flags.String("title", "", "Only include log entries with a title matching this regular expression"),
+	}
+}
+
+func (f *logFilterFlags) parse(root string) (*buildlog.LogFilter, error) {
+	filter := &buildlog.LogFilter{}
+	// Date range
+	if len(*f.from) > 0 {
+		if d, err := buildlog.ParseDateArg(*f.from); err != nil {
+			return nil, err
+		} else {
+			filter.From = d
+		}
+	}
+	if len(*f.to) > 0 {
+		if d, err := buildlog.ParseDateArg(*f.to); err != nil {
+			return nil, err
+		} else {
+			filter.To = d
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, errors.New("'to' must not be before 'from'")
+	}
+	// Assembly and subassemblies
+	if len(*f.assembly) > 0 || len(*f.subassembly) > 0 {
+		config, err := buildlog.ReadProjectConfig(root)
+		if err != nil {
+			return nil, err
+		}
+		if len(*f.assembly) > 0 {
+			if a, err := buildlog.ParseAssemblyArg(config, *f.assembly); err != nil {
+				return nil, err
+			} else {
+				filter.Assembly = a
+			}
+		}
+		if len(*f.subassembly) > 0 {
+			if s, err := buildlog.ParseSubassemblyArg(config, filter.Assembly, *f.subassembly); err != nil {
+				return nil, err
+			} else {
+				filter.Subassemblies = s
+			}
+		}
+	}
+	// Tags
+	if len(*f.tags) > 0 {
+		for _, t := range strings.Split(*f.tags, ",") {
+			if len(strings.TrimSpace(t)) == 0 {
+				return nil, errors.New("Tags must not be empty strings")
+			}
+			filter.Tags = append(filter.Tags, strings.TrimSpace(t))
+		}
+	} else if *f.allTags {
+		return nil, errors.New("'all-tags' requires 'tags'")
+	}
+	filter.AllTags = *f.allTags
+	// Duration
+	if *f.minDuration < 0 {
+		return nil, errors.New("'min-duration' must not be negative")
+	}
+	filter.MinMinutes = int(f.minDuration.Minutes())
+	// Title
+	if len(*f.titlePattern) > 0 {
+		re, err := regexp.Compile(*f.titlePattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid 'title' pattern\n%s", err.Error())
+		}
+		filter.Title = re
+	}
+	return filter, nil
+}
@@ -0,0 +1,261 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var HistoryCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "List recent changes to the logs",
+	},
+	parseHistory,
+	executeHistory)
+
+var UndoCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Revert the most recent changes to the logs",
+	},
+	parseUndo,
+	executeUndo)
+
+type historyArgs struct {
+	root  string
+	limit int
+	diff  bool
+}
+
+type undoArgs struct {
+	root  string
+	count int
+	yes   bool
+}
+
+func parseHistory(name string, argv []string) (*historyArgs, error) {
+	args := &historyArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	limit := flags.Int("n", 10, "Number of changes to list, most recent first; 0 for all")
+	diff := flags.Bool("diff", false, "Show the changed lines of each log entry")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("Unexpected argument \"%s\"", flags.Arg(0))
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	if *limit < 0 {
+		return nil, errors.New("'n' must not be negative")
+	}
+	args.limit = *limit
+	args.diff = *diff
+	return args, nil
+}
+
+func parseUndo(name string, argv []string) (*undoArgs, error) {
+	args := &undoArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s: %s [flags] [N]\n\nReverts the N most recent changes that have not already been undone (default 1), see 'history'.\n\n", name, name)
+		flags.PrintDefaults()
+	}
+	// Raw flags
+	yes := flags.Bool("y", false, "Revert without asking for confirmation")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	args.count = 1
+	if flags.NArg() > 1 {
+		return nil, fmt.Errorf("Unexpected argument \"%s\"", flags.Arg(1))
+	} else if flags.NArg() == 1 {
+		n, err := strconv.Atoi(flags.Arg(0))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("Number of changes to undo must be a positive number, got \"%s\"", flags.Arg(0))
+		}
+		args.count = n
+	}
+	args.yes = *yes
+	return args, nil
+}
+
+func formatJournalTimestamp(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.Local().Format(buildlog.DateLayout + " " + time.Kitchen)
+}
+
+type journalChangeResult struct {
+	ID string `json:"id"`
+	// One of added, removed or changed
+	Kind   string   `json:"kind"`
+	Title  string   `json:"title"`
+	Fields []string `json:"fields,omitempty"`
+	Diff   []string `json:"diff,omitempty"`
+}
+
+type journalEntryResult struct {
+	N         int                   `json:"n"`
+	Timestamp string                `json:"timestamp"`
+	Command   string                `json:"command"`
+	Undid     []uint32              `json:"undid,omitempty"`
+	UndoneBy  int                   `json:"undone_by,omitempty"`
+	Changes   []journalChangeResult `json:"changes"`
+}
+
+func newJournalEntryResult(n int, entry *protos.JournalEntry, undoneBy int, diff bool) journalEntryResult {
+	r := journalEntryResult{
+		N:         n,
+		Timestamp: entry.Timestamp,
+		Command:   entry.Command,
+		Undid:     entry.Undone,
+		UndoneBy:  undoneBy,
+		Changes:   []journalChangeResult{},
+	}
+	for _, change := range entry.Change {
+		r.Changes = append(r.Changes, newJournalChangeResult(change, diff))
+	}
+	return r
+}
+
+func newJournalChangeResult(change *protos.JournalChange, diff bool) journalChangeResult {
+	c := journalChangeResult{ID: buildlog.JournalChangeID(change)}
+	if change.Before == nil {
+		c.Kind, c.Title = "added", change.After.Title
+	} else if change.After == nil {
+		c.Kind, c.Title = "removed", change.Before.Title
+	} else {
+		c.Kind, c.Title, c.Fields = "changed", change.After.Title, buildlog.ChangedFields(change)
+	}
+	if diff {
+		c.Diff = buildlog.DiffJournalChange(change)
+	}
+	return c
+}
+
+func (c *journalChangeResult) PrintText(w io.Writer) {
+	switch c.Kind {
+	case "added":
+		fmt.Fprintf(w, "     + %s  %s\n", c.ID, c.Title)
+	case "removed":
+		fmt.Fprintf(w, "     - %s  %s\n", c.ID, c.Title)
+	default:
+		fmt.Fprintf(w, "     ~ %s  %s\n", c.ID, strings.Join(c.Fields, ", "))
+	}
+	for _, line := range c.Diff {
+		fmt.Fprintf(w, "         %s\n", line)
+	}
+}
+
+func (r *journalEntryResult) PrintText(w io.Writer) error {
+	command := r.Command
+	if len(command) == 0 {
+		command = "(unknown command)"
+	}
+	fmt.Fprintf(w, "%3d  %s  %s\n", r.N, formatJournalTimestamp(r.Timestamp), command)
+	if len(r.Undid) > 0 {
+		var undone []string
+		for _, u := range r.Undid {
+			undone = append(undone, strconv.Itoa(int(u)))
+		}
+		fmt.Fprintf(w, "       Undid %s\n", strings.Join(undone, ", "))
+	}
+	if r.UndoneBy > 0 {
+		fmt.Fprintf(w, "       Undone by %d\n", r.UndoneBy)
+	}
+	for _, c := range r.Changes {
+		c.PrintText(w)
+	}
+	return nil
+}
+
+type historyResult struct {
+	// Most recent first
+	Changes []journalEntryResult `json:"changes"`
+}
+
+func (r *historyResult) PrintText(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes have been recorded")
+		return err
+	}
+	for _, c := range r.Changes {
+		if err := c.PrintText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executeHistory(args *historyArgs) (cli.Result, error) {
+	journal, err := buildlog.ReadJournal(buildlog.JournalPath(buildlog.LogsPath(args.root)))
+	if err != nil {
+		return nil, err
+	}
+	result := &historyResult{Changes: []journalEntryResult{}}
+	undone := buildlog.UndoneJournalEntries(journal)
+	for n := len(journal.Entry); n > 0 && (args.limit == 0 || len(journal.Entry)-n < args.limit); n-- {
+		result.Changes = append(result.Changes, newJournalEntryResult(n, journal.Entry[n-1], undone[n], args.diff))
+	}
+	return result, nil
+}
+
+type undoResult struct {
+	Undone   []int  `json:"undone"`
+	LogsFile string `json:"logs_file"`
+}
+
+func (r *undoResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	return err
+}
+
+func executeUndo(args *undoArgs) (cli.Result, error) {
+	f := buildlog.LogsPath(args.root)
+	journal, err := buildlog.ReadJournal(buildlog.JournalPath(f))
+	if err != nil {
+		return nil, err
+	}
+	positions := buildlog.UndoableJournalEntries(journal, args.count)
+	if len(positions) == 0 {
+		return nil, cli.NewError(errorCodeNotFound, errors.New("Nothing to undo"))
+	} else if len(positions) < args.count {
+		return nil, cli.NewError(errorCodeNotFound, fmt.Errorf("Only %d change(s) can be undone, see 'history'", len(positions)))
+	}
+	if err := buildlog.CheckUndoable(journal, positions); err != nil {
+		return nil, err
+	}
+	for _, n := range positions {
+		entry := newJournalEntryResult(n, journal.Entry[n-1], 0, true)
+		entry.PrintText(os.Stdout)
+	}
+	if !args.yes && !confirm("\nRevert these changes?") {
+		return nil, cli.NewError(errorCodeAborted, errors.New("Aborted"))
+	}
+	if err := buildlog.UndoLogUpdates(args.root, journal, positions); err != nil {
+		return nil, err
+	}
+	return &undoResult{Undone: positions, LogsFile: f}, nil
+}
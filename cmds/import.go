@@ -0,0 +1,189 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var ImportCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Import work periods from a spreadsheet or another time tracker",
+	},
+	parseImport,
+	executeImport)
+
+type importArgs struct {
+	root     string
+	config   *protos.ProjectConfig
+	file     string
+	format   string
+	opts     *buildlog.ImportOptions
+	defaults buildlog.ImportDefaults
+	conflict string
+	dryRun   bool
+}
+
+func parseImport(name string, argv []string) (*importArgs, error) {
+	args := &importArgs{opts: &buildlog.ImportOptions{}}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s: %s [flags] FILE\n\n", name, name)
+		fmt.Fprintf(flags.Output(), "Reads a CSV file with a header row, a Toggl or Clockify CSV export, or the events of an iCalendar file.\n")
+		fmt.Fprintf(flags.Output(), "CSV columns are read by field name unless mapped with 'columns'; fields are: %s.\n\n", strings.Join(buildlog.ImportFields, ", "))
+		flags.PrintDefaults()
+	}
+	// Raw flags
+	format := flags.String("format", "", "Format of the file, one of: "+strings.Join(buildlog.ImportFormats(), ", ")+"; defaults to ics for .ics files, else csv")
+	columns := flags.String("columns", "", "Comma-separated mapping of fields to CSV columns, e.g., date=Day,title=Description")
+	assembly := flags.String("assembly", "", "Top-level assembly of work periods that do not name one")
+	attributionFlags := defineAttributionFlags(flags)
+	zone := flags.String("zone", buildlog.LocalTimeZone(), "Time zone of times that do not specify one")
+	conflict := flags.String("conflict", buildlog.ImportMerge, "How to import a log entry for an assembly already logged that day, one of: "+strings.Join(buildlog.ImportConflictModes, ", "))
+	dryRun := flags.Bool("n", false, "Show the changes that would be made to the logs without making them")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	if args.config, err = buildlog.ReadProjectConfig(root); err != nil {
+		return nil, err
+	}
+	// File
+	if flags.NArg() == 0 {
+		return nil, errors.New("A file to import is required")
+	} else if flags.NArg() > 1 {
+		return nil, fmt.Errorf("Unexpected argument \"%s\"", flags.Arg(1))
+	}
+	args.file = flags.Arg(0)
+	// Format
+	args.format = strings.ToLower(*format)
+	if len(args.format) == 0 {
+		args.format = buildlog.ImportFormatOfFile(args.file)
+	} else if !containsString(buildlog.ImportFormats(), args.format) {
+		return nil, fmt.Errorf("Format must be one of:\n  %s", strings.Join(buildlog.ImportFormats(), "\n  "))
+	}
+	// Columns
+	if len(*columns) > 0 {
+		if args.opts.Columns, err = buildlog.ParseImportColumnsArg(*columns); err != nil {
+			return nil, err
+		}
+	}
+	// Assembly
+	if len(*assembly) > 0 {
+		if args.defaults.Assembly, err = buildlog.ParseAssemblyArg(args.config, *assembly); err != nil {
+			return nil, err
+		}
+	}
+	// Builder
+	a, err := attributionFlags.parse(args.config)
+	if err != nil {
+		return nil, err
+	}
+	args.defaults.Builder, args.defaults.Helpers = a.builder, a.helpers
+	// Zone
+	if args.opts.Location, err = buildlog.LoadTimeZone(*zone); err != nil {
+		return nil, err
+	}
+	// Conflict
+	if !containsString(buildlog.ImportConflictModes, *conflict) {
+		return nil, fmt.Errorf("'conflict' must be one of:\n  %s", strings.Join(buildlog.ImportConflictModes, "\n  "))
+	}
+	args.conflict = *conflict
+	args.dryRun = *dryRun
+	return args, nil
+}
+
+type importResult struct {
+	buildlog.ImportSummary
+	Records int  `json:"records"`
+	DryRun  bool `json:"dry_run"`
+	// Records without a work period to import, e.g., work still ongoing when the file was exported
+	SkippedRecords []buildlog.SkippedImportRecord `json:"skipped_records,omitempty"`
+	// Only listed for a dry run
+	Changes  []journalChangeResult `json:"changes,omitempty"`
+	LogsFile string                `json:"logs_file,omitempty"`
+}
+
+func (r *importResult) PrintText(w io.Writer) error {
+	for _, c := range r.Changes {
+		c.PrintText(w)
+	}
+	if len(r.Changes) > 0 {
+		fmt.Fprintln(w)
+	}
+	verb := "Imported"
+	if r.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(w, "%s %d work periods from %d records: %d log entries added, %d merged, %d replaced, %d skipped\n",
+		verb, r.Periods, r.Records, r.Added, r.Merged, r.Replaced, r.Skipped)
+	if r.OverlappingPeriods > 0 {
+		fmt.Fprintf(w, "Left out %d work periods overlapping work already logged\n", r.OverlappingPeriods)
+	}
+	if len(r.SkippedRecords) > 0 {
+		fmt.Fprintf(w, "Left out %d records without a work period to import:\n", len(r.SkippedRecords))
+		for _, s := range r.SkippedRecords {
+			fmt.Fprintf(w, "  %s: %s\n", s.Source, s.Reason)
+		}
+	}
+	if len(r.LogsFile) > 0 {
+		fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	}
+	return nil
+}
+
+func executeImport(args *importArgs) (cli.Result, error) {
+	fp, err := os.Open(args.file)
+	if err != nil {
+		return nil, cli.NewError(errorCodeNotFound, err)
+	}
+	defer fp.Close()
+	records, err := buildlog.ReadImport(args.format, fp, args.opts)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read %s\n%s", args.file, err.Error())
+	}
+	entries, skipped, err := buildlog.ImportLogEntries(args.config, records, args.defaults)
+	if err != nil {
+		return nil, fmt.Errorf("Could not import %s\n%s", args.file, err.Error())
+	}
+
+	result := &importResult{Records: len(records), DryRun: args.dryRun, SkippedRecords: skipped}
+	f := buildlog.LogsPath(args.root)
+	update := buildlog.ImportLogUpdater(entries, args.conflict, &result.ImportSummary)
+	if args.dryRun {
+		changes, err := buildlog.PreviewLogUpdate(f, update)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			result.Changes = append(result.Changes, newJournalChangeResult(change, true))
+		}
+		return result, nil
+	}
+	if err := buildlog.UpdateLogMetadataFile(f, update); err != nil {
+		return nil, err
+	}
+	for _, entry := range result.AddedEntries() {
+		if exists, err := buildlog.FileExists(buildlog.LogDetailsPath(args.root, entry)); err != nil {
+			return nil, err
+		} else if !exists {
+			if _, err := buildlog.CreateLogDetailsFile(args.root, entry, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	result.LogsFile = f
+	return result, nil
+}
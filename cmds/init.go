@@ -0,0 +1,63 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var InitCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Create a new build log project",
+	},
+	parseInit,
+	executeInit)
+
+type initArgs struct {
+	dir string
+}
+
+func parseInit(name string, argv []string) (*initArgs, error) {
+	args := &initArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	// Directory, from either the positional arg or the global -C flag
+	if flags.NArg() > 1 {
+		return nil, errors.New("At most one project directory may be specified")
+	}
+	args.dir = flags.Arg(0)
+	if len(args.dir) == 0 {
+		args.dir = projectDir
+	}
+	if len(args.dir) == 0 {
+		args.dir = "."
+	}
+	return args, nil
+}
+
+type initResult struct {
+	Dir        string `json:"dir"`
+	ConfigFile string `json:"config_file"`
+	LogsFile   string `json:"logs_file"`
+}
+
+func (r *initResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Initialized build log project in %s\n\n", r.Dir)
+	fmt.Fprintf(w, "Project config:  %s\n", r.ConfigFile)
+	_, err := fmt.Fprintf(w, "Log file:        %s\n", r.LogsFile)
+	return err
+}
+
+func executeInit(args *initArgs) (cli.Result, error) {
+	if err := buildlog.InitProject(args.dir); err != nil {
+		return nil, err
+	}
+	return &initResult{Dir: args.dir, ConfigFile: buildlog.ConfigPath(args.dir), LogsFile: buildlog.LogsPath(args.dir)}, nil
+}
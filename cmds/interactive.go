@@ -5,7 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"math"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -21,7 +21,10 @@ const (
 )
 
 type startArgs struct {
-	assembly string
+	root          string
+	assembly      string
+	subassemblies []string
+	attribution   *attribution
 }
 
 var StartCmd = cli.ConstructCommand(
@@ -52,11 +55,22 @@ var StopCmd = cli.ConstructCommand(
 	parseStop,
 	executeStop)
 
+var PauseCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Take a break from working",
+	},
+	parsePause,
+	executePause)
+
+var ResumeCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Resume working after a break",
+	},
+	parseResume,
+	executeResume)
+
 func durationMinToString(minutes int) string {
-	if minutes == 0 {
-		return "0m"
-	}
-	return strings.TrimSuffix((time.Duration(minutes) * time.Minute).String(), "0s")
+	return buildlog.FormatDurationMin(minutes)
 }
 
 func parseStart(name string, argv []string) (*startArgs, error) {
@@ -64,18 +78,44 @@ func parseStart(name string, argv []string) (*startArgs, error) {
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
 	// Raw flags
 	assembly := flags.String("assembly", "", "Top-level assembly; if not set assumes unchanged from the prior log entry.")
+	subassembly := flags.String("subassembly", "", "Comma-separated list of subassemblies of the top-level assembly; requires 'assembly'")
+	attributionFlags := defineAttributionFlags(flags)
 	// Parse
 	if err := flags.Parse(argv); err != nil {
 		return nil, err
 	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	config, err := buildlog.ReadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
 	// Assembly
 	if len(*assembly) > 0 {
-		if a, err := buildlog.ParseAssemblyArg(*assembly); err != nil {
+		if a, err := buildlog.ParseAssemblyArg(config, *assembly); err != nil {
 			return nil, err
 		} else {
 			args.assembly = a
 		}
 	}
+	// Subassemblies
+	if len(*subassembly) > 0 {
+		if len(args.assembly) == 0 {
+			return nil, errors.New("'subassembly' requires 'assembly'")
+		}
+		if s, err := buildlog.ParseSubassemblyArg(config, args.assembly, *subassembly); err != nil {
+			return nil, err
+		} else {
+			args.subassemblies = s
+		}
+	}
+	// Builder
+	if args.attribution, err = attributionFlags.parse(config); err != nil {
+		return nil, err
+	}
 	return args, nil
 }
 
@@ -85,202 +125,832 @@ func StartLogUpdater(entry *protos.BuildLogEntry) buildlog.LogUpdater {
 		if err != nil {
 			return logs, err
 		}
-		exists, index := buildlog.LogExists(date, logs)
-		if exists {
-			merged := logs[index]
-			if len(merged.WorkPeriod) > 0 {
-				pw := merged.WorkPeriod[len(merged.WorkPeriod)-1]
-				if len(pw.EndTime) == 0 {
-					dur, err := buildlog.SameDayKitchenTimeDiff(entry.WorkPeriod[0].StartTime, pw.StartTime)
-					if err != nil {
-						return nil, err
-					}
-
-					return nil, fmt.Errorf(
-						"Work period already ongoing, started at %s (%d minutes ago). Run 'stop' to end this work period.",
-						pw.StartTime,
-						uint32(math.Ceil(dur.Minutes())))
-				}
+		// Builders each have their own work period, such that several may work at once
+		builder := entry.WorkPeriod[0].Builder
+		if open, ei, pi := buildlog.FindOpenWorkPeriod(logs, builder); open {
+			pw := logs[ei].WorkPeriod[pi]
+			start, err := buildlog.WorkPeriodStart(logs[ei], pw)
+			if err != nil {
+				return nil, err
 			}
+			return nil, cli.NewError(errorCodeAlreadyWorking, fmt.Errorf(
+				"Work period%s already ongoing, started %s at %s (%s ago). Run 'stop' to end this work period.",
+				byBuilder(builder),
+				logs[ei].Date,
+				pw.StartTime,
+				durationMinToString(int(time.Since(start).Minutes()))))
+		}
+		if paused, ei, pi := buildlog.FindPausedWorkPeriod(logs, builder); paused {
+			return nil, cli.NewError(errorCodePaused, fmt.Errorf(
+				"Work%s paused at %s on %s. Run 'resume' to continue working or 'stop' to end this session.",
+				byBuilder(builder),
+				logs[ei].WorkPeriod[pi].EndTime,
+				logs[ei].Date))
+		}
+		if len(entry.Assembly) == 0 {
+			if len(logs) == 0 {
+				return nil, errors.New("Assembly not specified but also no previous log entry exists from which to inherit")
+			}
+			// assume unchanged from the prior log entry
+			entry.Assembly = logs[len(logs)-1].Assembly
+		}
+		if exists, index := buildlog.FindLogEntry(logs, date, entry.Assembly); exists {
+			merged := logs[index]
 			merged.WorkPeriod = append(merged.WorkPeriod, entry.WorkPeriod[0])
-		} else {
-			if len(entry.Assembly) == 0 {
-				if len(logs) == 0 {
-					return nil, errors.New("Assembly not specified but also no previous log entry exists from which to inherit")
+			for _, sa := range entry.Subassembly {
+				if !containsString(merged.Subassembly, sa) {
+					merged.Subassembly = append(merged.Subassembly, sa)
 				}
-				// assume unchanged from the prior log entry
-				entry.Assembly = logs[len(logs)-1].Assembly
 			}
-			logs = append(logs, entry)
+			entry.Id = buildlog.LogEntryID(merged)
+			entry.DetailsFile = merged.DetailsFile
+		} else {
+			logs = buildlog.AppendLogEntry(logs, entry)
 		}
 		return logs, nil
 	}
 }
 
-func executeStart(args *startArgs) error {
+// Wraps an updater to retain the updated log entries, such that those affected can be included in a result
+func retainLogs(update buildlog.LogUpdater, retained *[]*protos.BuildLogEntry) buildlog.LogUpdater {
+	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		logs, err := update(logs)
+		*retained = logs
+		return logs, err
+	}
+}
+
+// Returns the log entry with the given ID as updated, falling back to entry if not found
+func updatedEntry(logs []*protos.BuildLogEntry, entry *protos.BuildLogEntry) *protos.BuildLogEntry {
+	if exists, index := buildlog.FindLogEntryByID(logs, entry.Id); exists {
+		return logs[index]
+	}
+	return entry
+}
+
+type startResult struct {
+	detailsEditor
+	started     time.Time
+	Started     string       `json:"started"`
+	Entry       *entryResult `json:"entry"`
+	LogsFile    string       `json:"logs_file"`
+	DetailsFile string       `json:"details_file"`
+}
+
+func (r *startResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Started a new work period at %s on log entry %s (%s)\n\n", r.started.Format(time.Kitchen), r.Entry.ID, r.Entry.Assembly)
+	fmt.Fprintf(w, "Updated log file:   %s\n", r.LogsFile)
+	_, err := fmt.Fprintf(w, "Details file:  %s\n", r.DetailsFile)
+	return err
+}
+
+func executeStart(args *startArgs) (cli.Result, error) {
 	now := time.Now()
 	entry := protos.BuildLogEntry{
-		Assembly: args.assembly,
-		Date:     buildlog.FormatDateForLog(now),
+		Assembly:    args.assembly,
+		Subassembly: args.subassemblies,
+		Date:        buildlog.FormatDateForLog(now),
 		WorkPeriod: []*protos.TimePeriod{
-			{
-				StartTime:   now.Format(time.Kitchen),
-				EndTime:     "",
-				DurationMin: 0,
-			}},
-		DetailsFile: buildlog.LogDetailsFile(now),
+			buildlog.NewWorkPeriod(now, time.Time{}),
+		},
 	}
+	args.attribution.apply(entry.WorkPeriod)
 
-	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath, StartLogUpdater(&entry)); err != nil {
-		return err
+	var logs []*protos.BuildLogEntry
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(args.root), retainLogs(StartLogUpdater(&entry), &logs)); err != nil {
+		return nil, err
 	}
-	fmt.Printf("Started a new work period at %s\n\n", now.Format(time.Kitchen))
-	fmt.Printf("Updated log file:   %s\n", buildlog.LogsPath)
-	if f, err := buildlog.CreateLogDetailsFile(args.assembly, now, false); err != nil {
-		return err
-	} else {
-		fmt.Printf("Details file:  %s\n", f)
-		return buildlog.LaunchEditor(f)
+	f := buildlog.LogDetailsPath(args.root, &entry)
+	if exists, err := buildlog.FileExists(f); err != nil {
+		return nil, err
+	} else if !exists {
+		if _, err := buildlog.CreateLogDetailsFile(args.root, &entry, false); err != nil {
+			return nil, err
+		}
 	}
+	return &startResult{
+		detailsEditor: detailsEditor{f},
+		started:       now,
+		Started:       now.Format(time.RFC3339),
+		Entry:         newEntryResult(args.root, updatedEntry(logs, &entry)),
+		LogsFile:      buildlog.LogsPath(args.root),
+		DetailsFile:   f,
+	}, nil
 }
 
 type statusArgs struct {
-	date time.Time
+	root     string
+	selector *entrySelector
+	prompt   bool
+	format   string
 }
 
 func parseStatus(name string, argv []string) (*statusArgs, error) {
 	args := &statusArgs{}
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
 	// Raw flags
-	date := flags.String("date", "today", "Date of work. Supported forms: "+strings.Join(buildlog.ValidDateFormats(), ", "))
+	selector := defineEntrySelectorFlags(flags)
+	prompt := flags.Bool("prompt", false, "Print only a compact summary of the ongoing or paused session, or nothing, for use in a shell prompt or status bar")
+	format := flags.String("format", buildlog.DefaultTimerFormat, "Format of the summary printed by 'prompt': %a assembly, %d time elapsed, %i icon, %% percent sign")
 	// Parse
 	if err := flags.Parse(argv); err != nil {
 		return nil, err
 	}
-	// Date
-	if d, err := buildlog.ParseDateArg(*date); err != nil {
+	args.prompt = *prompt
+	args.format = *format
+	if args.prompt {
+		// Prompts are shown in every directory, so outside of a project there is simply nothing to show
+		args.root, _ = findProjectRoot()
+		return args, nil
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Log entry
+	if sel, err := selector.parse(root); err != nil {
 		return nil, err
 	} else {
-		args.date = d
+		args.selector = sel
 	}
 	return args, nil
 }
 
-func executeStatus(args *statusArgs) error {
-	logs, err := buildlog.ReadLogs(buildlog.LogsPath)
+type statusResult struct {
+	// Ongoing or paused sessions of work, one per builder
+	Sessions []*sessionResult `json:"sessions"`
+	Entries  []*entryResult   `json:"entries"`
+}
+
+func (r *statusResult) PrintText(w io.Writer) error {
+	for _, session := range r.Sessions {
+		session.PrintText(w)
+	}
+	for n, entry := range r.Entries {
+		if n > 0 {
+			fmt.Fprintln(w)
+		}
+		date, _ := time.Parse(buildlog.DateLayout, entry.Date)
+		fmt.Fprintf(w, "%s  %s (%s):  ", date.Format(humanReadableDate), entry.ID, entry.Assembly)
+		if len(entry.Periods) > 0 {
+			pw := entry.Periods[len(entry.Periods)-1]
+			if pw.Ongoing {
+				fmt.Fprintf(w, "Ongoing work period started at %s\n\nRun 'pause' to take a break or 'stop' to end this work period\n", pw.StartTime)
+			} else {
+				fmt.Fprintf(w, "Total logged work %s\n", durationMinToString(entry.Minutes))
+			}
+		} else {
+			fmt.Fprintf(w, "Log entry exists but without any work periods\n")
+		}
+	}
+	return nil
+}
+
+type promptResult struct {
+	Prompt string `json:"prompt"`
+}
+
+func (r *promptResult) PrintText(w io.Writer) error {
+	if len(r.Prompt) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, r.Prompt)
+	return err
+}
+
+func executePrompt(args *statusArgs) (cli.Result, error) {
+	if len(args.root) == 0 {
+		return &promptResult{}, nil
+	}
+	timers, err := buildlog.ReadTimers(args.root)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return &promptResult{Prompt: buildlog.FormatTimers(args.format, timers, time.Now())}, nil
+}
 
-	exists, index := buildlog.LogExists(args.date, logs.LogEntry)
-	if !exists {
-		return fmt.Errorf("No log entry found for %s\n\nCreate a log entry using 'log' or 'start'\n", args.date.Format(humanReadableDate))
+func executeStatus(args *statusArgs) (cli.Result, error) {
+	if args.prompt {
+		return executePrompt(args)
 	}
-	fmt.Printf("%s:  ", args.date.Format(humanReadableDate))
-	entry := logs.LogEntry[index]
-	if len(entry.WorkPeriod) > 0 {
-		pw := entry.WorkPeriod[len(entry.WorkPeriod)-1]
-		if len(pw.EndTime) == 0 {
-			fmt.Printf("Ongoing work period started at %s\n\nRun 'stop' to end this work period\n", pw.StartTime)
-		} else {
-			total := 0
-			for _, wp := range entry.WorkPeriod {
-				total += int(wp.DurationMin)
-			}
-			fmt.Printf("Total logged work %s\n", durationMinToString(total))
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &statusResult{
+		Sessions: []*sessionResult{},
+		Entries:  []*entryResult{},
+	}
+	now := time.Now()
+	for _, builder := range buildlog.ActiveBuilders(logs.LogEntry) {
+		result.Sessions = append(result.Sessions, newSessionResult(logs.LogEntry, builder, now))
+	}
+
+	indices, err := args.selector.findAll(logs.LogEntry)
+	if err != nil {
+		// Sessions are still reported
+		return result, err
+	}
+	for _, index := range indices {
+		result.Entries = append(result.Entries, newEntryResult(args.root, logs.LogEntry[index]))
+	}
+	return result, nil
+}
+
+// Time worked by a builder in their ongoing or paused session of work and on the day so far
+type sessionResult struct {
+	since, start time.Time
+	// Empty for work not attributed to anyone
+	Builder string `json:"builder,omitempty"`
+	Working bool   `json:"working"`
+	// When the ongoing work period started or, if paused, when work was paused
+	Since         string `json:"since"`
+	SinceMinutes  int    `json:"since_minutes"`
+	BreakNote     string `json:"break_note,omitempty"`
+	Start         string `json:"start"`
+	WorkedMinutes int    `json:"worked_minutes"`
+	BreakMinutes  int    `json:"break_minutes"`
+	TodayMinutes  int    `json:"today_minutes"`
+}
+
+// Returns nil if the builder's work is neither ongoing nor paused
+func newSessionResult(logs []*protos.BuildLogEntry, builder string, now time.Time) *sessionResult {
+	session := buildlog.CurrentSession(logs, builder)
+	if len(session) == 0 {
+		return nil
+	}
+	r := &sessionResult{start: session[0].Start, Builder: builder}
+	for i, ref := range session {
+		r.WorkedMinutes += ref.Minutes(now)
+		if i > 0 {
+			r.BreakMinutes += int(ref.Start.Sub(session[i-1].End).Minutes())
 		}
+	}
+	last := session[len(session)-1]
+	if wp := last.Period(logs); buildlog.IsOpenWorkPeriod(wp) {
+		r.Working = true
+		r.since = last.Start
+		r.SinceMinutes = last.Minutes(now)
 	} else {
-		fmt.Printf("Log entry exists but without any work periods\n")
+		r.since = last.End
+		r.SinceMinutes = int(now.Sub(last.End).Minutes())
+		r.BreakNote = wp.BreakNote
+		r.BreakMinutes += r.SinceMinutes
 	}
-	return nil
+	r.Since = r.since.Format(time.RFC3339)
+	r.Start = r.start.Format(time.RFC3339)
+	for _, ref := range buildlog.BuilderWorkPeriods(logs, builder) {
+		if ref.Start.Format(buildlog.DateLayout) == now.Format(buildlog.DateLayout) {
+			r.TodayMinutes += ref.Minutes(now)
+		}
+	}
+	return r
+}
+
+func (r *sessionResult) PrintText(w io.Writer) {
+	if len(r.Builder) > 0 {
+		fmt.Fprintf(w, "%s:  ", r.Builder)
+	}
+	if r.Working {
+		fmt.Fprintf(w, "Working since %s (%s)\n", r.since.Format(time.Kitchen), durationMinToString(r.SinceMinutes))
+	} else {
+		fmt.Fprintf(w, "Paused since %s", r.since.Format(time.Kitchen))
+		if len(r.BreakNote) > 0 {
+			fmt.Fprintf(w, " (%s)", r.BreakNote)
+		}
+		fmt.Fprintf(w, ", %s ago\n", durationMinToString(r.SinceMinutes))
+	}
+	fmt.Fprintf(w, "Session since %s:  %s worked, %s on breaks\n", r.start.Format(time.Kitchen), durationMinToString(r.WorkedMinutes), durationMinToString(r.BreakMinutes))
+	fmt.Fprintf(w, "Worked today so far:  %s\n\n", durationMinToString(r.TodayMinutes))
 }
 
 type editArgs struct {
-	date time.Time
+	root     string
+	selector *entrySelector
 }
 
 func parseEdit(name string, argv []string) (*editArgs, error) {
 	args := &editArgs{}
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
 	// Raw flags
-	date := flags.String("date", "today", "Date of work. Supported forms: "+strings.Join(buildlog.ValidDateFormats(), ", "))
+	selector := defineEntrySelectorFlags(flags)
 	// Parse
 	if err := flags.Parse(argv); err != nil {
 		return nil, err
 	}
-	// Date
-	if d, err := buildlog.ParseDateArg(*date); err != nil {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Log entry
+	if sel, err := selector.parse(root); err != nil {
 		return nil, err
 	} else {
-		args.date = d
+		args.selector = sel
 	}
 	return args, nil
 }
 
-func executeEdit(args *editArgs) error {
-	logs, err := buildlog.ReadLogs(buildlog.LogsPath)
+type editResult struct {
+	detailsEditor
+	Entry       *entryResult `json:"entry"`
+	DetailsFile string       `json:"details_file"`
+}
+
+func (r *editResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Editing details for %s %s\n\nDetails file:  %s\n", r.Entry.ID, r.Entry.Assembly, r.DetailsFile)
+	return err
+}
+
+func executeEdit(args *editArgs) (cli.Result, error) {
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	exists, _ := buildlog.LogExists(args.date, logs.LogEntry)
-	if !exists {
-		return fmt.Errorf("No log entry found for %s. Create a log entry using 'log' or 'start'.", args.date.Format(humanReadableDate))
+	index, err := args.selector.find(logs.LogEntry)
+	if err != nil {
+		return nil, err
 	}
-	df := buildlog.LogDetailsFile(args.date)
-	fmt.Printf("Editing details for %s\n\nDetails file:  %s\n", args.date.Format(humanReadableDateShort), df)
-	return buildlog.LaunchEditor(df)
+	entry := logs.LogEntry[index]
+	df := buildlog.LogDetailsPath(args.root, entry)
+	return &editResult{
+		detailsEditor: detailsEditor{df},
+		Entry:         newEntryResult(args.root, entry),
+		DetailsFile:   df,
+	}, nil
 }
 
-func parseStop(name string, argv []string) (*any, error) {
-	return nil, nil
+type stopArgs struct {
+	root     string
+	end      time.Time
+	maxAge   time.Duration
+	force    bool
+	assembly string
+	// Builder given by -builder, if any, and the caller, to whom work is otherwise attributed by default
+	builder string
+	caller  string
 }
 
-func StopLogUpdater(now time.Time) buildlog.LogUpdater {
+func parseStop(name string, argv []string) (*stopArgs, error) {
+	args := &stopArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	maxAge := flags.Duration("maxage", 16*time.Hour, "Ask for confirmation before stopping a work period that has been ongoing for longer than this")
+	force := flags.Bool("force", false, "Stop the ongoing work period without asking for confirmation, regardless of how long ago it started")
+	date := flags.String("date", "", "Date on which work stopped; requires 'time'. Supported forms: "+strings.Join(buildlog.ValidDateFormats(), ", "))
+	endTime := flags.String("time", "", "Time at which work stopped, e.g., 5:30pm; defaults to now")
+	builder := flags.String("builder", "", "Builder whose work period to stop, if not the caller per $"+buildlog.BuilderEnvVar+"; attributes the work period to them if it was started without a builder")
+	assembly := flags.String("assembly", "", "Top-level assembly of the work period to stop, to choose between several builders working at once")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Builder
+	if args.builder, args.caller, err = parseBuilderSelection(root, *builder); err != nil {
+		return nil, err
+	}
+	// Assembly
+	if len(*assembly) > 0 {
+		config, err := buildlog.ReadProjectConfig(root)
+		if err != nil {
+			return nil, err
+		}
+		if args.assembly, err = buildlog.ParseAssemblyArg(config, *assembly); err != nil {
+			return nil, err
+		}
+	}
+	// End time
+	if len(*endTime) > 0 {
+		d := time.Now()
+		if len(*date) > 0 {
+			var err error
+			if d, err = buildlog.ParseDateArg(*date); err != nil {
+				return nil, err
+			}
+		}
+		if t, err := buildlog.ParseKitchenTime(d.Year(), d.Month(), d.Day(), *endTime, time.Local); err != nil {
+			return nil, err
+		} else {
+			args.end = t
+		}
+	} else if len(*date) > 0 {
+		return nil, errors.New("'date' requires 'time'")
+	}
+	if *maxAge <= 0 {
+		return nil, errors.New("'maxage' must be positive")
+	}
+	args.maxAge = *maxAge
+	args.force = *force
+	return args, nil
+}
+
+func promptLine(prompt string) string {
+	fmt.Printf("%s\n> ", prompt)
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	return scanner.Text()
+}
+
+func confirm(prompt string) bool {
+	answer := strings.ToLower(strings.TrimSpace(promptLine(prompt + " [y/N]")))
+	return answer == "y" || answer == "yes"
+}
+
+type stopResult struct {
+	entry     *protos.BuildLogEntry
+	start     time.Time
+	end       time.Time
+	startTime string
+	// Log entries created for the remainder of a work period split at midnight
+	created []*protos.BuildLogEntry
+	Entry   *entryResult `json:"entry"`
+	// Start is not set when stopping a paused session, which ends when work was paused
+	Start           string `json:"start,omitempty"`
+	End             string `json:"end"`
+	Minutes         int    `json:"minutes"`
+	SplitAtMidnight bool   `json:"split_at_midnight,omitempty"`
+	PausedSession   bool   `json:"paused_session,omitempty"`
+	DayMinutes      int    `json:"day_minutes"`
+	LogsFile        string `json:"logs_file"`
+}
+
+func (r *stopResult) PrintText(w io.Writer) error {
+	if r.PausedSession {
+		fmt.Fprintf(w, "Stopped paused session, work ended at %s\n", r.startTime)
+	} else if r.SplitAtMidnight {
+		fmt.Fprintf(w, "Stopped work period, %s %s to %s %s (%d minutes, split at midnight)\n",
+			r.start.Format(humanReadableDateShort), r.startTime, r.end.Format(humanReadableDateShort), r.end.Format(time.Kitchen), r.Minutes)
+	} else {
+		fmt.Fprintf(w, "Stopped work period, %s to %s (%d minutes)\n", r.startTime, r.end.Format(time.Kitchen), r.Minutes)
+	}
+	fmt.Fprintf(w, "\nLog Entry:  %s\n", r.Entry.Title)
+	fmt.Fprintf(w, "Total time worked %s:  %s\n", r.end.Format(humanReadableDate), durationMinToString(r.DayMinutes))
+	_, err := fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	return err
+}
+
+// The work period that stop ends: the builder's ongoing work period, else the last of their paused session
+type stopTarget struct {
+	paused      bool
+	entryIndex  int
+	periodIndex int
+}
+
+func findStopTarget(logs []*protos.BuildLogEntry, args *stopArgs) (stopTarget, error) {
+	active := buildersWorkingOn(logs, buildlog.ActiveBuilders(logs), args.assembly)
+	builder, ok, err := chooseBuilder(active, args.builder, args.caller)
+	if err != nil {
+		return stopTarget{}, err
+	} else if !ok {
+		return stopTarget{}, cli.NewError(errorCodeNotWorking, fmt.Errorf("No ongoing work period%s, run 'start' to begin working", byBuilder(builder)))
+	}
+	if open, ei, pi := buildlog.FindOpenWorkPeriod(logs, builder); open {
+		return stopTarget{false, ei, pi}, nil
+	}
+	_, ei, pi := buildlog.FindPausedWorkPeriod(logs, builder)
+	return stopTarget{true, ei, pi}, nil
+}
+
+// Answers to the prompts of stop, which are given before the logs are locked such that a builder at a prompt does not
+// hold up others starting or stopping work
+type stopAnswers struct {
+	// Log entry and start of the work period to which the answers apply
+	entryID string
+	start   time.Time
+	title   string
+}
+
+// Asks for confirmation to stop a work period ongoing for longer than maxAge, and for a title if the log entry has none
+func promptStop(end time.Time, args *stopArgs) (*stopAnswers, error) {
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	buildlog.AssignLogEntryIDs(logs.LogEntry)
+	target, err := findStopTarget(logs.LogEntry, args)
+	if err != nil {
+		return nil, err
+	}
+	entry := logs.LogEntry[target.entryIndex]
+	pw := entry.WorkPeriod[target.periodIndex]
+	start, err := buildlog.WorkPeriodStart(entry, pw)
+	if err != nil {
+		return nil, err
+	}
+	if age := end.Sub(start); !target.paused && age > args.maxAge && !args.force {
+		fmt.Printf("Work period would span %s, having started on %s at %s\n\n", durationMinToString(int(age.Minutes())), start.Format(humanReadableDate), pw.StartTime)
+		if !confirm("Stop this work period anyway?") {
+			return nil, cli.NewError(errorCodeAborted, fmt.Errorf("Work period left ongoing; run 'stop -date %s -time TIME' to record the actual end time", entry.Date))
+		}
+		fmt.Println()
+	}
+	answers := &stopAnswers{entryID: buildlog.LogEntryID(entry), start: start}
+	if len(entry.Title) == 0 {
+		answers.title = promptLine("Enter title for log entry")
+		fmt.Println()
+	}
+	return answers, nil
+}
+
+func StopLogUpdater(end time.Time, args *stopArgs, answers *stopAnswers, result *stopResult) buildlog.LogUpdater {
 	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
-		exists, index := buildlog.LogExists(now, logs)
-		if !exists {
-			return nil, fmt.Errorf("No log entry exists for today (%s)", now.Format(humanReadableDate))
+		target, err := findStopTarget(logs, args)
+		if err != nil {
+			return nil, err
+		}
+		ei, pi := target.entryIndex, target.periodIndex
+		merged := logs[ei]
+		pw := merged.WorkPeriod[pi]
+		start, err := buildlog.WorkPeriodStart(merged, pw)
+		if err != nil {
+			return nil, err
+		}
+		// The answers no longer apply if the work period was stopped or changed by another command in the meantime
+		if buildlog.LogEntryID(merged) != answers.entryID || !start.Equal(answers.start) {
+			return nil, errors.New("The work period changed while stopping it, run 'stop' again")
 		}
-		merged := logs[index]
-		if len(merged.WorkPeriod) == 0 {
-			return nil, fmt.Errorf("Log entry exists for today but there are no work periods (weird), run 'start' to begin working")
+		if len(merged.Title) == 0 {
+			merged.Title = answers.title
 		}
-		pw := merged.WorkPeriod[len(merged.WorkPeriod)-1]
-		if len(pw.EndTime) != 0 {
-			return nil, fmt.Errorf("Work period already stopped at %s, run 'start' to begin working again", pw.StartTime)
+		if target.paused {
+			return stopPausedSession(logs, ei, pi, result)
 		}
-		pw.EndTime = now.Format(time.Kitchen)
-		dm, err := buildlog.SameDayKitchenTimeDiff(pw.EndTime, pw.StartTime)
+
+		if len(pw.Builder) == 0 {
+			if len(args.builder) > 0 {
+				pw.Builder = args.builder
+			} else if open, _, _ := buildlog.FindOpenWorkPeriod(logs, args.caller); !open {
+				// Unless the caller is already working, in which case this is not their work
+				pw.Builder = args.caller
+			}
+		}
+
+		n := len(logs)
+		logs, dm, err := buildlog.CloseWorkPeriod(logs, ei, pi, end)
 		if err != nil {
 			return nil, err
 		}
-		pw.DurationMin = uint32(math.Ceil(dm.Minutes()))
-		fmt.Printf("Stopped work period, %s to %s (%d minutes)\n", pw.StartTime, pw.EndTime, pw.DurationMin)
+		result.entry = merged
+		// Copied, since the logs are sorted in place once updated
+		result.created = append([]*protos.BuildLogEntry{}, logs[n:]...)
+		result.start, result.end, result.startTime = start, end, pw.StartTime
+		result.Start = start.Format(time.RFC3339)
+		result.End = end.Format(time.RFC3339)
+		result.Minutes = int(dm)
+		result.SplitAtMidnight = start.Format(buildlog.DateLayout) != end.Format(buildlog.DateLayout)
+		result.DayMinutes = dayMinutes(logs, end)
+		return logs, nil
+	}
+}
 
-		if len(merged.Title) == 0 {
-			fmt.Printf("\nEnter title for log entry\n> ")
-			scanner := bufio.NewScanner(os.Stdin)
-			scanner.Scan()
-			merged.Title = scanner.Text()
-		} else {
-			fmt.Printf("\nLog Entry:  %s\n", merged.Title)
+func dayMinutes(logs []*protos.BuildLogEntry, date time.Time) int {
+	total := 0
+	for _, index := range buildlog.FindLogEntries(logs, date, "") {
+		total += buildlog.LogEntryMinutes(logs[index])
+	}
+	return total
+}
+
+// Ends a paused session of work at the time it was paused
+func stopPausedSession(logs []*protos.BuildLogEntry, entryIndex int, periodIndex int, result *stopResult) ([]*protos.BuildLogEntry, error) {
+	entry := logs[entryIndex]
+	pw := entry.WorkPeriod[periodIndex]
+	end, err := buildlog.WorkPeriodEnd(entry, pw)
+	if err != nil {
+		return nil, err
+	}
+	pw.Paused = false
+	result.entry = entry
+	result.end, result.startTime = end, pw.EndTime
+	result.End = end.Format(time.RFC3339)
+	result.PausedSession = true
+	result.DayMinutes = dayMinutes(logs, end)
+	return logs, nil
+}
+
+func executeStop(args *stopArgs) (cli.Result, error) {
+	root := args.root
+	end := args.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	answers, err := promptStop(end, args)
+	if err != nil {
+		return nil, err
+	}
+	result := &stopResult{LogsFile: buildlog.LogsPath(root)}
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(root), StopLogUpdater(end, args, answers, result)); err != nil {
+		return nil, err
+	}
+	for _, entry := range result.created {
+		if exists, err := buildlog.FileExists(buildlog.LogDetailsPath(root, entry)); err != nil {
+			return nil, err
+		} else if !exists {
+			if _, err := buildlog.CreateLogDetailsFile(root, entry, false); err != nil {
+				return nil, err
+			}
 		}
+	}
+	result.Entry = newEntryResult(root, result.entry)
+	return result, nil
+}
+
+type pauseArgs struct {
+	root string
+	note string
+	// Builder given by -builder, if any, and the caller
+	builder string
+	caller  string
+}
 
-		total := 0
-		for _, wp := range merged.WorkPeriod {
-			total += int(wp.DurationMin)
+func parsePause(name string, argv []string) (*pauseArgs, error) {
+	args := &pauseArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	note := flags.String("note", "", "Note about the break, e.g., lunch")
+	builder := flags.String("builder", "", "Builder whose work to pause, if not the caller per $"+buildlog.BuilderEnvVar)
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	args.note = *note
+	// Builder
+	if args.builder, args.caller, err = parseBuilderSelection(root, *builder); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+type pauseResult struct {
+	entry     *protos.BuildLogEntry
+	paused    time.Time
+	Paused    string       `json:"paused"`
+	Minutes   int          `json:"minutes"`
+	BreakNote string       `json:"break_note,omitempty"`
+	Entry     *entryResult `json:"entry"`
+	LogsFile  string       `json:"logs_file"`
+}
+
+func (r *pauseResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Paused work at %s after %s\n\nRun 'resume' to continue working\n", r.paused.Format(time.Kitchen), durationMinToString(r.Minutes))
+	_, err := fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	return err
+}
+
+func PauseLogUpdater(end time.Time, args *pauseArgs, result *pauseResult) buildlog.LogUpdater {
+	note := args.note
+	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		builder, ok, err := chooseBuilder(workingBuilders(logs), args.builder, args.caller)
+		if err != nil {
+			return nil, err
 		}
-		fmt.Printf("Total time worked %s:  %s\n", now.Format(humanReadableDate), durationMinToString(total))
+		open, ei, pi := buildlog.FindOpenWorkPeriod(logs, builder)
+		if !ok || !open {
+			if paused, _, _ := buildlog.FindPausedWorkPeriod(logs, builder); paused {
+				return nil, cli.NewError(errorCodePaused, fmt.Errorf("Work%s is already paused, run 'resume' to continue working", byBuilder(builder)))
+			}
+			return nil, cli.NewError(errorCodeNotWorking, fmt.Errorf("No ongoing work period%s, run 'start' to begin working", byBuilder(builder)))
+		}
+		entry := logs[ei]
+		logs, dm, err := buildlog.PauseWorkPeriod(logs, ei, pi, end, note)
+		if err != nil {
+			return nil, err
+		}
+		result.entry = entry
+		result.paused = end
+		result.Paused = end.Format(time.RFC3339)
+		result.Minutes = int(dm)
+		result.BreakNote = note
 		return logs, nil
 	}
 }
 
-func executeStop(_ *any) error {
+func executePause(args *pauseArgs) (cli.Result, error) {
+	result := &pauseResult{LogsFile: buildlog.LogsPath(args.root)}
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(args.root), PauseLogUpdater(time.Now(), args, result)); err != nil {
+		return nil, err
+	}
+	result.Entry = newEntryResult(args.root, result.entry)
+	return result, nil
+}
+
+type resumeArgs struct {
+	root string
+	note string
+	// Builder given by -builder, if any, and the caller
+	builder string
+	caller  string
+}
+
+func parseResume(name string, argv []string) (*resumeArgs, error) {
+	args := &resumeArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	note := flags.String("note", "", "Note about the break, replacing any given to 'pause'")
+	builder := flags.String("builder", "", "Builder whose work to resume, if not the caller per $"+buildlog.BuilderEnvVar)
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	args.note = *note
+	// Builder
+	if args.builder, args.caller, err = parseBuilderSelection(root, *builder); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// Starts a new work period continuing the paused session, on the log entry for the same assembly today
+func ResumeLogUpdater(now time.Time, args *resumeArgs, entry *protos.BuildLogEntry, result *resumeResult) buildlog.LogUpdater {
+	note := args.note
+	return func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		builder, ok, err := chooseBuilder(pausedBuilders(logs), args.builder, args.caller)
+		if err != nil {
+			return nil, err
+		}
+		paused, ei, pi := buildlog.FindPausedWorkPeriod(logs, builder)
+		if !ok || !paused {
+			if open, _, _ := buildlog.FindOpenWorkPeriod(logs, builder); open {
+				return nil, cli.NewError(errorCodeAlreadyWorking, fmt.Errorf("Work%s is not paused, run 'pause' to take a break", byBuilder(builder)))
+			}
+			return nil, cli.NewError(errorCodeNotWorking, fmt.Errorf("No paused work%s, run 'start' to begin working", byBuilder(builder)))
+		}
+		prev := logs[ei]
+		pw := prev.WorkPeriod[pi]
+		if len(note) > 0 {
+			pw.BreakNote = note
+		}
+		end, err := buildlog.WorkPeriodEnd(prev, pw)
+		if err != nil {
+			return nil, err
+		}
+		// Unpause so that the new work period is not refused as starting during a paused session
+		pw.Paused = false
+		entry.Assembly = prev.Assembly
+		entry.Subassembly = prev.Subassembly
+		entry.Tags = prev.Tags
+		entry.Title = prev.Title
+		buildlog.AttributeWorkPeriod(entry.WorkPeriod[0], pw.Builder, pw.Helper)
+		logs, err = StartLogUpdater(entry)(logs)
+		if err != nil {
+			return nil, err
+		}
+		pw.Paused = true
+		result.resumed = now
+		result.Resumed = now.Format(time.RFC3339)
+		result.BreakMinutes = int(now.Sub(end).Minutes())
+		result.BreakNote = pw.BreakNote
+		return logs, nil
+	}
+}
+
+type resumeResult struct {
+	resumed      time.Time
+	Resumed      string       `json:"resumed"`
+	BreakMinutes int          `json:"break_minutes"`
+	BreakNote    string       `json:"break_note,omitempty"`
+	Entry        *entryResult `json:"entry"`
+	LogsFile     string       `json:"logs_file"`
+}
+
+func (r *resumeResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Resumed work at %s after a break of %s\n", r.resumed.Format(time.Kitchen), durationMinToString(r.BreakMinutes))
+	_, err := fmt.Fprintf(w, "\nLog entry %s (%s)\nUpdated log file:   %s\n", r.Entry.ID, r.Entry.Assembly, r.LogsFile)
+	return err
+}
+
+func executeResume(args *resumeArgs) (cli.Result, error) {
 	now := time.Now()
-	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath, StopLogUpdater(now)); err != nil {
-		return err
+	entry := protos.BuildLogEntry{
+		Date: buildlog.FormatDateForLog(now),
+		WorkPeriod: []*protos.TimePeriod{
+			buildlog.NewWorkPeriod(now, time.Time{}),
+		},
 	}
-	fmt.Printf("\nUpdated log entry for %s in log file:   %s\n", now.Format(humanReadableDateShort), buildlog.LogsPath)
-	return nil
+	result := &resumeResult{LogsFile: buildlog.LogsPath(args.root)}
+	var logs []*protos.BuildLogEntry
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(args.root), retainLogs(ResumeLogUpdater(now, args, &entry, result), &logs)); err != nil {
+		return nil, err
+	}
+	result.Entry = newEntryResult(args.root, updatedEntry(logs, &entry))
+	return result, nil
 }
@@ -0,0 +1,180 @@
+package cmds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Creates a project with builders Craig and Alex, in which Craig left a work period open on 2024-Dec-23 before
+// logging other work, and selects it as the project of the commands run by the test
+func testProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	config := "builder: <\n  name: \"Craig\"\n>\nbuilder: <\n  name: \"Alex\"\n>\n"
+	if err := os.WriteFile(buildlog.ConfigPath(root), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stale := buildlog.NewWorkPeriod(time.Date(2024, time.December, 23, 13, 28, 0, 0, time.Local), time.Time{})
+	stale.Builder = "Craig"
+	later := time.Date(2025, time.January, 19, 9, 0, 0, 0, time.Local)
+	logged := buildlog.NewWorkPeriod(later, later.Add(2*time.Hour))
+	logged.Builder = "Craig"
+	logs := &protos.BuildLogs{LogEntry: []*protos.BuildLogEntry{
+		{Id: "2024-Dec-23", Date: "2024-Dec-23", Assembly: "fuselage", Title: "Rivet longerons", WorkPeriod: []*protos.TimePeriod{stale}},
+		{Id: "2025-Jan-19", Date: "2025-Jan-19", Assembly: "fuselage", Title: "Prime firewall", WorkPeriod: []*protos.TimePeriod{logged}},
+	}}
+	if err := buildlog.WriteLogs(buildlog.LogsPath(root), logs); err != nil {
+		t.Fatal(err)
+	}
+	SetProjectDir(root)
+	t.Cleanup(func() { SetProjectDir("") })
+
+	// Prompts for a title are answered with an empty line, and their output discarded
+	stdin, err := os.Create(filepath.Join(t.TempDir(), "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin.WriteString("\n\n\n\n")
+	stdin.Seek(0, 0)
+	devnull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevStdin, prevStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, devnull
+	t.Cleanup(func() {
+		os.Stdin, os.Stdout = prevStdin, prevStdout
+		stdin.Close()
+		devnull.Close()
+	})
+	return root
+}
+
+func runCommand(t *testing.T, cmd cli.Command, name string, argv ...string) (cli.Result, error) {
+	t.Helper()
+	return cmd.ParseArgsAndExecute(name, argv)
+}
+
+func testSessions(t *testing.T) map[string]*sessionResult {
+	t.Helper()
+	// Sessions are reported even without a log entry for today
+	result, err := runCommand(t, StatusCmd, "status")
+	if err != nil && cli.ErrorCode(err) != errorCodeNotFound {
+		t.Fatal(err)
+	}
+	sessions := map[string]*sessionResult{}
+	for _, s := range result.(*statusResult).Sessions {
+		sessions[s.Builder] = s
+	}
+	return sessions
+}
+
+func TestStartStopPauseWithOpenPeriodNotLatest(t *testing.T) {
+	root := testProject(t)
+	t.Setenv(buildlog.BuilderEnvVar, "Craig")
+
+	// The open work period is ongoing although Craig has since logged other work
+	if s := testSessions(t)["Craig"]; s == nil || !s.Working {
+		t.Fatalf("Craig's session is %+v, expected to be working", s)
+	}
+	timers, err := buildlog.ReadTimers(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timers) != 1 || timers[0].Builder != "Craig" {
+		t.Errorf("Timers are %v, expected one for Craig", timers)
+	}
+	if _, err := runCommand(t, StartCmd, "start", "-assembly", "fuselage"); cli.ErrorCode(err) != errorCodeAlreadyWorking {
+		t.Errorf("Start while working failed with %v, expected %s", err, errorCodeAlreadyWorking)
+	}
+	if _, err := runCommand(t, ResumeCmd, "resume"); cli.ErrorCode(err) != errorCodeAlreadyWorking {
+		t.Errorf("Resume while working failed with %v, expected %s", err, errorCodeAlreadyWorking)
+	}
+
+	result, err := runCommand(t, StopCmd, "stop", "-date", "2024-Dec-23", "-time", "5pm", "-force")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped := result.(*stopResult); stopped.Minutes != 212 || stopped.Entry.Date != "2024-Dec-23" {
+		t.Errorf("Stopped %d minutes on %s, expected 212 on 2024-Dec-23", stopped.Minutes, stopped.Entry.Date)
+	}
+	if sessions := testSessions(t); len(sessions) != 0 {
+		t.Errorf("Sessions after stopping are %v, expected none", sessions)
+	}
+	if _, err := runCommand(t, StopCmd, "stop"); cli.ErrorCode(err) != errorCodeNotWorking {
+		t.Errorf("Stop while not working failed with %v, expected %s", err, errorCodeNotWorking)
+	}
+
+	// Builders each have their own work period
+	if _, err := runCommand(t, StartCmd, "start", "-assembly", "fuselage"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, StartCmd, "start", "-assembly", "left wing", "-builder", "Alex"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, PauseCmd, "pause", "-note", "lunch"); err != nil {
+		t.Fatal(err)
+	}
+	sessions := testSessions(t)
+	if s := sessions["Craig"]; s == nil || s.Working || s.BreakNote != "lunch" {
+		t.Errorf("Craig's session is %+v, expected to be paused for lunch", s)
+	}
+	if s := sessions["Alex"]; s == nil || !s.Working {
+		t.Errorf("Alex's session is %+v, expected to be working", s)
+	}
+	if _, err := runCommand(t, StartCmd, "start", "-assembly", "fuselage"); cli.ErrorCode(err) != errorCodePaused {
+		t.Errorf("Start while paused failed with %v, expected %s", err, errorCodePaused)
+	}
+	if _, err := runCommand(t, ResumeCmd, "resume"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, StopCmd, "stop"); err != nil {
+		t.Fatal(err)
+	}
+	sessions = testSessions(t)
+	if _, exists := sessions["Craig"]; exists || len(sessions) != 1 {
+		t.Errorf("Sessions after Craig stopped are %v, expected only Alex's", sessions)
+	}
+	if _, err := runCommand(t, StopCmd, "stop", "-builder", "Alex"); err != nil {
+		t.Fatal(err)
+	}
+	if sessions := testSessions(t); len(sessions) != 0 {
+		t.Errorf("Sessions after both stopped are %v, expected none", sessions)
+	}
+}
+
+func TestStopSplitAtMidnight(t *testing.T) {
+	root := testProject(t)
+	t.Setenv(buildlog.BuilderEnvVar, "Craig")
+
+	result, err := runCommand(t, StopCmd, "stop", "-date", "2024-Dec-24", "-time", "1am", "-force")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped := result.(*stopResult); !stopped.SplitAtMidnight || stopped.Minutes != 692 {
+		t.Errorf("Stopped %d minutes, split %v, expected 692 minutes split at midnight", stopped.Minutes, stopped.SplitAtMidnight)
+	}
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exists, index := buildlog.FindLogEntryByID(logs.LogEntry, "2024-Dec-24")
+	if !exists {
+		t.Fatal("No log entry was created for the remainder on 2024-Dec-24")
+	}
+	entry := logs.LogEntry[index]
+	if entry.DetailsFile != "2024-Dec/2024-Dec-24.md" {
+		t.Errorf("Remainder has details file %s, expected its own", entry.DetailsFile)
+	}
+	if exists, err := buildlog.FileExists(buildlog.LogDetailsPath(root, entry)); err != nil {
+		t.Fatal(err)
+	} else if !exists {
+		t.Errorf("Details file %s of the remainder was not created", entry.DetailsFile)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 	"unicode"
@@ -25,12 +26,15 @@ var LogCmd = cli.ConstructCommand(
 	execute)
 
 type logArgs struct {
-	assembly    string
-	date        time.Time
-	workPeriods []*protos.TimePeriod
-	title       string
-	tags        []string
-	overwrite   bool
+	root          string
+	assembly      string
+	subassemblies []string
+	date          time.Time
+	workPeriods   []*protos.TimePeriod
+	title         string
+	tags          []string
+	overwrite     bool
+	attribution   *attribution
 }
 
 func parse(name string, argv []string) (*logArgs, error) {
@@ -38,11 +42,13 @@ func parse(name string, argv []string) (*logArgs, error) {
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
 	// Raw flags
 	assembly := flags.String("assembly", "", "Top-level assembly; required.")
+	subassembly := flags.String("subassembly", "", "Comma-separated list of subassemblies of the top-level assembly")
 	date := flags.String("date", "", "Date of work; required. Supported forms: "+strings.Join(buildlog.ValidDateFormats(), ", "))
 	workPeriods := flags.String("time", "", "Time period(s) of work. Required.")
 	title := flags.String("title", "", "Title for the log entry")
 	tags := flags.String("tags", "", "Comma-separated list of arbitrary tags")
-	overwrite := flags.Bool("overwrite", false, "Replace existing log entry on specified date")
+	overwrite := flags.Bool("overwrite", false, "Replace existing log entry for the assembly on specified date")
+	attributionFlags := defineAttributionFlags(flags)
 	// Parse
 	if err := flags.Parse(argv); err != nil {
 		return nil, err
@@ -51,11 +57,28 @@ func parse(name string, argv []string) (*logArgs, error) {
 	if len(*assembly) == 0 {
 		return nil, errors.New("'assembly' is required")
 	}
-	if a, err := buildlog.ParseAssemblyArg(*assembly); err != nil {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	config, err := buildlog.ReadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	if a, err := buildlog.ParseAssemblyArg(config, *assembly); err != nil {
 		return nil, err
 	} else {
 		args.assembly = a
 	}
+	// Subassemblies
+	if len(*subassembly) > 0 {
+		if s, err := buildlog.ParseSubassemblyArg(config, args.assembly, *subassembly); err != nil {
+			return nil, err
+		} else {
+			args.subassemblies = s
+		}
+	}
 	// Date
 	if len(*date) == 0 {
 		return nil, errors.New("'date' is required")
@@ -74,6 +97,11 @@ func parse(name string, argv []string) (*logArgs, error) {
 	} else {
 		args.workPeriods = w
 	}
+	// Builder
+	if args.attribution, err = attributionFlags.parse(config); err != nil {
+		return nil, err
+	}
+	args.attribution.apply(args.workPeriods)
 	if len(*title) == 0 {
 		return nil, errors.New("'title' is required")
 	}
@@ -97,13 +125,26 @@ func parse(name string, argv []string) (*logArgs, error) {
 	return args, nil
 }
 
-func execute(args *logArgs) error {
+type logResult struct {
+	detailsEditor
+	Entry       *entryResult `json:"entry"`
+	LogsFile    string       `json:"logs_file"`
+	DetailsFile string       `json:"details_file"`
+}
+
+func (r *logResult) PrintText(w io.Writer) error {
+	fmt.Fprintf(w, "Logged %s:   %s\n", r.Entry.ID, r.LogsFile)
+	_, err := fmt.Fprintf(w, "Details:  %s\n", r.DetailsFile)
+	return err
+}
+
+func execute(args *logArgs) (cli.Result, error) {
 	entry := protos.BuildLogEntry{
 		Assembly:    args.assembly,
+		Subassembly: args.subassemblies,
 		Date:        buildlog.FormatDateForLog(args.date),
 		WorkPeriod:  args.workPeriods,
 		Title:       args.title,
-		DetailsFile: buildlog.LogDetailsFile(args.date),
 		Tags:        args.tags,
 	}
 
@@ -113,14 +154,21 @@ func execute(args *logArgs) error {
 		update = buildlog.UpsertLogUpdater(&entry)
 	}
 
-	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath, update); err != nil {
-		return err
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(args.root), update); err != nil {
+		return nil, err
 	}
-	fmt.Printf("Logged:   %s\n", buildlog.LogsPath)
-	if f, err := buildlog.CreateLogDetailsFile(args.assembly, args.date, false); err != nil {
-		return err
-	} else {
-		fmt.Printf("Details:  %s\n", f)
-		return buildlog.LaunchEditor(f)
+	f := buildlog.LogDetailsPath(args.root, &entry)
+	if exists, err := buildlog.FileExists(f); err != nil {
+		return nil, err
+	} else if !exists {
+		if _, err := buildlog.CreateLogDetailsFile(args.root, &entry, false); err != nil {
+			return nil, err
+		}
 	}
+	return &logResult{
+		detailsEditor: detailsEditor{f},
+		Entry:         newEntryResult(args.root, &entry),
+		LogsFile:      buildlog.LogsPath(args.root),
+		DetailsFile:   f,
+	}, nil
 }
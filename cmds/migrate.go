@@ -0,0 +1,80 @@
+package cmds
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var MigrateCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Backfill timestamps of work periods logged as kitchen times",
+	},
+	parseMigrate,
+	executeMigrate)
+
+type migrateArgs struct {
+	zone string
+}
+
+func parseMigrate(name string, argv []string) (*migrateArgs, error) {
+	args := &migrateArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	zone := flags.String("zone", buildlog.LocalTimeZone(), "Time zone in which existing kitchen times were recorded, e.g., America/Denver")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	// Zone
+	if _, err := buildlog.LoadTimeZone(*zone); err != nil {
+		return nil, err
+	}
+	args.zone = *zone
+	return args, nil
+}
+
+type migrateResult struct {
+	Backfilled int `json:"backfilled"`
+	// Work periods whose recorded duration did not match their timestamps, e.g., across a daylight saving time change
+	Corrected int    `json:"corrected"`
+	Zone      string `json:"zone"`
+	LogsFile  string `json:"logs_file"`
+}
+
+func (r *migrateResult) PrintText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Backfilled timestamps of %d work periods (%s)\n", r.Backfilled, r.Zone); err != nil {
+		return err
+	}
+	if r.Corrected > 0 {
+		if _, err := fmt.Fprintf(w, "Corrected the duration of %d work periods\n", r.Corrected); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\nUpdated log file:   %s\n", r.LogsFile)
+	return err
+}
+
+func executeMigrate(args *migrateArgs) (cli.Result, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	loc, err := buildlog.LoadTimeZone(args.zone)
+	if err != nil {
+		return nil, err
+	}
+	count, corrected := 0, 0
+	update := func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+		count, corrected, err = buildlog.BackfillWorkPeriodTimestamps(logs, loc)
+		return logs, err
+	}
+	if err := buildlog.UpdateLogMetadataFile(buildlog.LogsPath(root), update); err != nil {
+		return nil, err
+	}
+	return &migrateResult{Backfilled: count, Corrected: corrected, Zone: loc.String(), LogsFile: buildlog.LogsPath(root)}, nil
+}
@@ -0,0 +1,21 @@
+package cmds
+
+import (
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+// Project directory specified by the global -C flag, if any
+var projectDir string
+
+func SetProjectDir(dir string) {
+	projectDir = dir
+}
+
+func findProjectRoot() (string, error) {
+	root, err := buildlog.FindProjectRoot(projectDir)
+	if err != nil {
+		return "", cli.NewError(errorCodeNoProject, err)
+	}
+	return root, nil
+}
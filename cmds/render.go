@@ -3,12 +3,12 @@ package cmds
 import (
 	"errors"
 	"flag"
-	"os"
+	"fmt"
+	"io"
 	"strings"
 
 	"github.com/cragcraig/ccub/buildlog"
 	"github.com/cragcraig/ccub/cli"
-	"github.com/cragcraig/ccub/protos"
 )
 
 var RenderCmd = cli.ConstructCommand(
@@ -18,57 +18,130 @@ var RenderCmd = cli.ConstructCommand(
 	parseRender,
 	executeRender)
 
+// Flag that may be repeated to accumulate values
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringListFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
 type renderArgs struct {
-	tmplFile string
+	root      string
+	tmplFiles []string
+	exec      string
+	all       bool
+	filter    *buildlog.LogFilter
+	sortBy    string
+	reverse   bool
+	limit     int
 }
 
 func parseRender(name string, argv []string) (*renderArgs, error) {
 	args := &renderArgs{}
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
 	// Raw flags
-	tmplFile := flags.String("tmpl", "", "Template text file. Required.")
+	var tmplFiles stringListFlag
+	flags.Var(&tmplFiles, "tmpl", "Template text file or glob pattern. Required. Repeat to load partials and layouts, which all files in the set may use")
+	exec := flags.String("exec", "", "Name of the template in the set to execute; defaults to the first template file")
+	all := flags.Bool("all", false, "Execute the template once for the entire log, with its groups and totals, rather than once per log entry")
+	filterFlags := defineLogFilterFlags(flags)
+	sortBy := flags.String("sort", "date", "Order of log entries, one of: "+strings.Join(buildlog.ValidRenderSortKeys(), ", "))
+	reverse := flags.Bool("reverse", false, "Reverse the order of log entries")
+	limit := flags.Int("limit", 0, "Maximum number of log entries, after ordering; 0 for unlimited")
 	// Parse
 	if err := flags.Parse(argv); err != nil {
 		return nil, err
 	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
 	// Template
-	if len(*tmplFile) == 0 {
+	if len(tmplFiles) == 0 {
 		return nil, errors.New("'tmpl' is required")
 	}
-	args.tmplFile = *tmplFile
+	args.tmplFiles = tmplFiles
+	args.exec = *exec
+	args.all = *all
+	// Selection
+	if args.filter, err = filterFlags.parse(root); err != nil {
+		return nil, err
+	}
+	if !containsString(buildlog.ValidRenderSortKeys(), *sortBy) {
+		return nil, fmt.Errorf("Sort order must be one of:\n  %s", strings.Join(buildlog.ValidRenderSortKeys(), "\n  "))
+	}
+	args.sortBy = *sortBy
+	args.reverse = *reverse
+	if *limit < 0 {
+		return nil, errors.New("'limit' must not be negative")
+	}
+	args.limit = *limit
 	return args, nil
 }
 
-func executeRender(args *renderArgs) error {
-	tmpl, err := buildlog.LoadTemplateFromFile(args.tmplFile)
+type renderResult struct {
+	Output string `json:"output"`
+}
+
+func (r *renderResult) PrintText(w io.Writer) error {
+	_, err := io.WriteString(w, r.Output)
+	return err
+}
+
+func executeRender(args *renderArgs) (cli.Result, error) {
+	tmpl, err := buildlog.LoadRenderTemplates(args.tmplFiles)
+	if err != nil {
+		return nil, err
+	}
+	if len(args.exec) > 0 {
+		if tmpl = tmpl.Lookup(args.exec); tmpl == nil {
+			return nil, cli.NewError(errorCodeNotFound, fmt.Errorf("No template named %s", args.exec))
+		}
+	}
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil {
+		return nil, err
+	}
+	selected, err := buildlog.FilterLogs(logs.LogEntry, args.filter)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	data, err := buildlog.NewRenderData(args.root, selected)
+	if err != nil {
+		return nil, err
+	}
+	// Groups and running totals retain chronological order
+	if data.Entries, err = buildlog.SortRenderEntries(args.sortBy, data.Entries); err != nil {
+		return nil, err
+	}
+	if args.reverse {
+		data.Entries = buildlog.ReverseRenderEntries(data.Entries)
+	}
+	if args.limit > 0 {
+		data.Entries = buildlog.LimitRenderEntries(args.limit, data.Entries)
 	}
-	logs, err := buildlog.ReadLogs(buildlog.LogsPath)
 
-	// Render each log entry
-	for _, log := range logs.LogEntry {
-		// Read in details file
-		details, err := buildlog.ReadFile(strings.Join([]string{buildlog.LogsDir, log.DetailsFile}, "/"))
-		if err != nil {
-			if os.IsNotExist(err) {
-				details = "No details"
-			} else {
-				return err
-			}
+	var out strings.Builder
+	if args.all {
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, err
 		}
-		// Render log entry using template
-		data := struct {
-			*protos.BuildLogEntry
-			Details string
-		}{
-			BuildLogEntry: log,
-			Details:       strings.TrimSpace(details),
+		return &renderResult{Output: out.String()}, nil
+	}
+	// Render each log entry
+	for _, entry := range data.Entries {
+		if len(entry.Details) == 0 {
+			entry.Details = "No details"
 		}
-		if err := tmpl.Execute(os.Stdout, data); err != nil {
-			return err
+		if err := tmpl.Execute(&out, entry); err != nil {
+			return nil, err
 		}
 	}
-
-	return nil
+	return &renderResult{Output: out.String()}, nil
 }
@@ -0,0 +1,173 @@
+package cmds
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var ReportCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Report total hours worked by assembly, tag, builder or time period",
+	},
+	parseReport,
+	executeReport)
+
+var validReportFormats = []string{"table", "csv", "json"}
+
+type reportArgs struct {
+	root     string
+	grouping string
+	filter   *buildlog.LogFilter
+	format   string
+}
+
+func parseReport(name string, argv []string) (*reportArgs, error) {
+	args := &reportArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	by := flags.String("by", "assembly", "Group hours by one of: "+strings.Join(buildlog.ValidReportGroupings(), ", "))
+	filterFlags := defineLogFilterFlags(flags)
+	format := flags.String("format", "table", "Output format, one of: "+strings.Join(validReportFormats, ", "))
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Grouping
+	if g, err := buildlog.ParseReportGroupingArg(*by); err != nil {
+		return nil, err
+	} else {
+		args.grouping = g
+	}
+	// Selection
+	if args.filter, err = filterFlags.parse(root); err != nil {
+		return nil, err
+	}
+	// Format
+	if !containsString(validReportFormats, *format) {
+		return nil, fmt.Errorf("Format must be one of:\n  %s", strings.Join(validReportFormats, "\n  "))
+	}
+	args.format = *format
+	return args, nil
+}
+
+// Columns of hours by builder follow the totals of each group, if any work is attributed to a builder
+func writeReportTable(w io.Writer, grouping string, totals []buildlog.HoursTotal, total buildlog.HoursTotal, builders []string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tentries\thours", strings.ToUpper(grouping[:1])+grouping[1:])
+	for _, b := range builders {
+		fmt.Fprintf(tw, "\t%s", b)
+	}
+	fmt.Fprintln(tw)
+	for _, t := range totals {
+		fmt.Fprintf(tw, "%s\t%d\t%s", t.Group, t.Entries, buildlog.FormatHours(t.Minutes))
+		for _, b := range builders {
+			fmt.Fprintf(tw, "\t%s", buildlog.FormatHours(t.BuilderMinutes[b]))
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "%s\t%d\t%s", total.Group, total.Entries, buildlog.FormatHours(total.Minutes))
+	for _, b := range builders {
+		fmt.Fprintf(tw, "\t%s", buildlog.FormatHours(total.BuilderMinutes[b]))
+	}
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
+
+func writeReportCsv(w io.Writer, grouping string, totals []buildlog.HoursTotal, builders []string) error {
+	cw := csv.NewWriter(w)
+	header := []string{grouping, "entries", "minutes", "hours"}
+	for _, b := range builders {
+		header = append(header, b+" minutes")
+	}
+	cw.Write(header)
+	for _, t := range totals {
+		row := []string{t.Group, strconv.Itoa(t.Entries), strconv.Itoa(t.Minutes), buildlog.FormatHours(t.Minutes)}
+		for _, b := range builders {
+			row = append(row, strconv.Itoa(t.BuilderMinutes[b]))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type reportRow struct {
+	buildlog.HoursTotal
+	Hours float64 `json:"hours"`
+}
+
+type reportResult struct {
+	format  string
+	GroupBy string      `json:"group_by"`
+	Totals  []reportRow `json:"totals"`
+	// Of the selected log entries, each counted once even if it belongs to several groups
+	Total reportRow `json:"total"`
+	// Builders to whom work is attributed, in roster order; empty if no work is attributed to anyone
+	Builders []string `json:"builders,omitempty"`
+}
+
+func (r *reportResult) hoursTotals() []buildlog.HoursTotal {
+	var totals []buildlog.HoursTotal
+	for _, row := range r.Totals {
+		totals = append(totals, row.HoursTotal)
+	}
+	return totals
+}
+
+func (r *reportResult) PrintText(w io.Writer) error {
+	switch r.format {
+	case "csv":
+		return writeReportCsv(w, r.GroupBy, r.hoursTotals(), r.Builders)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	default:
+		return writeReportTable(w, r.GroupBy, r.hoursTotals(), r.Total.HoursTotal, r.Builders)
+	}
+}
+
+func executeReport(args *reportArgs) (cli.Result, error) {
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil {
+		return nil, err
+	}
+	selected, err := buildlog.FilterLogs(logs.LogEntry, args.filter)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := buildlog.HoursReport(selected, args.grouping)
+	if err != nil {
+		return nil, err
+	}
+	total := buildlog.HoursReportTotal(selected)
+	result := &reportResult{format: args.format, GroupBy: args.grouping, Totals: []reportRow{}}
+	for _, t := range totals {
+		result.Totals = append(result.Totals, reportRow{HoursTotal: t, Hours: t.Hours()})
+	}
+	result.Total = reportRow{HoursTotal: total, Hours: total.Hours()}
+	if args.grouping != "builder" && buildlog.HasAttributedWork(selected) {
+		config, err := buildlog.ReadProjectConfig(args.root)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range buildlog.BuilderTotals(config, selected) {
+			result.Builders = append(result.Builders, t.Builder)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var RestoreCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "List or restore backups of the log file",
+	},
+	parseRestore,
+	executeRestore)
+
+type restoreArgs struct {
+	n int
+}
+
+func parseRestore(name string, argv []string) (*restoreArgs, error) {
+	args := &restoreArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	n := flags.Int("n", 0, "Restore the n-th most recent backup, starting from 1; if not set lists available backups")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	if *n < 0 {
+		return nil, errors.New("'n' must be positive")
+	}
+	args.n = *n
+	return args, nil
+}
+
+const backupTimeLayout = "Jan 02, 2006 3:04:05PM"
+
+type backupResult struct {
+	N int `json:"n"`
+	// RFC 3339 timestamp at which the backup was made
+	Created string `json:"created"`
+	// Number of log entries, or -1 if the backup could not be read
+	Entries int `json:"entries"`
+}
+
+type restoreResult struct {
+	// Available backups, most recent first, if listing rather than restoring
+	Backups []backupResult `json:"backups,omitempty"`
+	// Backup restored, if any
+	Restored *backupResult `json:"restored,omitempty"`
+	LogsFile string        `json:"logs_file"`
+}
+
+func formatBackupTime(created string) string {
+	if t, err := time.Parse(time.RFC3339Nano, created); err == nil {
+		return t.Local().Format(backupTimeLayout)
+	}
+	return created
+}
+
+func (r *restoreResult) PrintText(w io.Writer) error {
+	if r.Restored != nil {
+		_, err := fmt.Fprintf(w, "Restored backup from %s\n\nUpdated log file:   %s\n", formatBackupTime(r.Restored.Created), r.LogsFile)
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, b := range r.Backups {
+		entries := "?"
+		if b.Entries >= 0 {
+			entries = fmt.Sprint(b.Entries)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s entries\n", b.N, formatBackupTime(b.Created), entries)
+	}
+	tw.Flush()
+	_, err := fmt.Fprintf(w, "\nRun 'restore -n N' to restore a backup\n")
+	return err
+}
+
+func executeRestore(args *restoreArgs) (cli.Result, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	f := buildlog.LogsPath(root)
+	backups, err := buildlog.ListBackups(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, cli.NewError(errorCodeNotFound, fmt.Errorf("No backups of %s exist", f))
+	}
+	result := &restoreResult{LogsFile: f}
+
+	// List backups
+	if args.n == 0 {
+		for i, b := range backups {
+			entries := -1
+			if logs, err := buildlog.ReadLogs(b.Path); err == nil {
+				entries = len(logs.LogEntry)
+			}
+			result.Backups = append(result.Backups, backupResult{N: i + 1, Created: b.Created.Format(time.RFC3339Nano), Entries: entries})
+		}
+		return result, nil
+	}
+
+	// Restore backup
+	if args.n > len(backups) {
+		return nil, cli.NewError(errorCodeNotFound, fmt.Errorf("Only %d backups exist", len(backups)))
+	}
+	b := backups[args.n-1]
+	if err := buildlog.RestoreBackup(f, b); err != nil {
+		return nil, err
+	}
+	result.Restored = &backupResult{N: args.n, Created: b.Created.Format(time.RFC3339Nano), Entries: -1}
+	if logs, err := buildlog.ReadLogs(f); err == nil {
+		result.Restored.Entries = len(logs.LogEntry)
+	}
+	return result, nil
+}
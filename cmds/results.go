@@ -0,0 +1,106 @@
+package cmds
+
+import (
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Codes of errors returned by commands, in addition to those of the cli package
+const (
+	errorCodeNoProject      = "no_project"
+	errorCodeNotFound       = "not_found"
+	errorCodeAmbiguous      = "ambiguous"
+	errorCodeNotWorking     = "not_working"
+	errorCodeAlreadyWorking = "already_working"
+	errorCodePaused         = "paused"
+	errorCodeAborted        = "aborted"
+	errorCodeProblems       = "problems_found"
+)
+
+// Work period as included in the JSON results of commands
+type periodResult struct {
+	// RFC 3339 timestamps, unless the work period cannot be placed in time
+	Start     string   `json:"start,omitempty"`
+	End       string   `json:"end,omitempty"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time,omitempty"`
+	Minutes   int      `json:"minutes"`
+	Ongoing   bool     `json:"ongoing,omitempty"`
+	Paused    bool     `json:"paused,omitempty"`
+	BreakNote string   `json:"break_note,omitempty"`
+	Builder   string   `json:"builder,omitempty"`
+	Helpers   []string `json:"helpers,omitempty"`
+}
+
+// Log entry as included in the JSON results of commands
+type entryResult struct {
+	ID            string         `json:"id"`
+	Date          string         `json:"date"`
+	Assembly      string         `json:"assembly"`
+	Subassemblies []string       `json:"subassemblies,omitempty"`
+	Title         string         `json:"title"`
+	Tags          []string       `json:"tags,omitempty"`
+	Minutes       int            `json:"minutes"`
+	Periods       []periodResult `json:"periods"`
+	DetailsFile   string         `json:"details_file,omitempty"`
+	Attachments   []string       `json:"attachments,omitempty"`
+}
+
+func newPeriodResult(entry *protos.BuildLogEntry, wp *protos.TimePeriod) periodResult {
+	r := periodResult{
+		StartTime: wp.StartTime,
+		EndTime:   wp.EndTime,
+		Minutes:   int(wp.DurationMin),
+		Ongoing:   buildlog.IsOpenWorkPeriod(wp),
+		Paused:    wp.Paused,
+		BreakNote: wp.BreakNote,
+		Builder:   wp.Builder,
+		Helpers:   wp.Helper,
+	}
+	if start, err := buildlog.WorkPeriodStart(entry, wp); err == nil {
+		r.Start = start.Format(time.RFC3339)
+		if r.Ongoing {
+			r.Minutes = int(time.Since(start).Minutes())
+		}
+	}
+	if !r.Ongoing {
+		if end, err := buildlog.WorkPeriodEnd(entry, wp); err == nil {
+			r.End = end.Format(time.RFC3339)
+		}
+	}
+	return r
+}
+
+func newEntryResult(root string, entry *protos.BuildLogEntry) *entryResult {
+	r := &entryResult{
+		ID:            buildlog.LogEntryID(entry),
+		Date:          entry.Date,
+		Assembly:      entry.Assembly,
+		Subassemblies: entry.Subassembly,
+		Title:         entry.Title,
+		Tags:          entry.Tags,
+		Minutes:       buildlog.LogEntryMinutes(entry),
+		Periods:       []periodResult{},
+	}
+	for _, wp := range entry.WorkPeriod {
+		r.Periods = append(r.Periods, newPeriodResult(entry, wp))
+	}
+	if len(entry.DetailsFile) > 0 {
+		r.DetailsFile = buildlog.LogDetailsPath(root, entry)
+	}
+	for _, a := range entry.Attachment {
+		r.Attachments = append(r.Attachments, buildlog.AttachmentPath(root, a))
+	}
+	return r
+}
+
+// Launches the editor on a details file once a result is printed as text
+type detailsEditor struct {
+	detailsFile string
+}
+
+func (e detailsEditor) Interact() error {
+	return buildlog.LaunchEditor(e.detailsFile)
+}
@@ -0,0 +1,118 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var SearchCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Search log entry titles, tags and details",
+	},
+	parseSearch,
+	executeSearch)
+
+type searchArgs struct {
+	root   string
+	query  *buildlog.SearchQuery
+	filter *buildlog.LogFilter
+	limit  int
+}
+
+func parseSearch(name string, argv []string) (*searchArgs, error) {
+	args := &searchArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s: %s [flags] QUERY\n\n", name, name)
+		fmt.Fprintf(flags.Output(), "Words and \"quoted phrases\" must all match unless joined by OR; prefix with - or NOT to exclude; end a word with * to match by prefix.\n\n")
+		flags.PrintDefaults()
+	}
+	// Raw flags
+	filterFlags := defineLogFilterFlags(flags)
+	limit := flags.Int("limit", 20, "Maximum number of results; 0 for unlimited")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	// Query
+	if flags.NArg() == 0 {
+		return nil, errors.New("Search query is required")
+	}
+	if q, err := buildlog.ParseSearchQuery(strings.Join(flags.Args(), " ")); err != nil {
+		return nil, err
+	} else {
+		args.query = q
+	}
+	// Selection
+	if args.filter, err = filterFlags.parse(root); err != nil {
+		return nil, err
+	}
+	if *limit < 0 {
+		return nil, errors.New("'limit' must not be negative")
+	}
+	args.limit = *limit
+	return args, nil
+}
+
+type searchMatch struct {
+	Entry   *entryResult `json:"entry"`
+	Snippet string       `json:"snippet,omitempty"`
+}
+
+type searchResult struct {
+	Matches []searchMatch `json:"matches"`
+}
+
+func (r *searchResult) PrintText(w io.Writer) error {
+	for _, m := range r.Matches {
+		fmt.Fprintf(w, "%s  %s  (%s)\n", m.Entry.ID, m.Entry.Title, m.Entry.Assembly)
+		if len(m.Snippet) > 0 {
+			fmt.Fprintf(w, "    %s\n", m.Snippet)
+		}
+	}
+	return nil
+}
+
+func executeSearch(args *searchArgs) (cli.Result, error) {
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil {
+		return nil, err
+	}
+	details, err := buildlog.UpdateSearchIndex(args.root, logs.LogEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &searchResult{Matches: []searchMatch{}}
+	for _, r := range buildlog.Search(logs.LogEntry, details, args.query) {
+		entry := logs.LogEntry[r.Index]
+		if match, err := args.filter.Matches(entry); err != nil {
+			return nil, err
+		} else if !match {
+			continue
+		}
+		if args.limit > 0 && len(result.Matches) == args.limit {
+			break
+		}
+		m := searchMatch{Entry: newEntryResult(args.root, entry)}
+		if text, err := buildlog.ReadLogDetails(args.root, entry); err == nil {
+			m.Snippet = args.query.Snippet(text)
+		}
+		result.Matches = append(result.Matches, m)
+	}
+	if len(result.Matches) == 0 {
+		return result, cli.NewError(errorCodeNotFound, errors.New("No matching log entries"))
+	}
+	return result, nil
+}
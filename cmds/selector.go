@@ -0,0 +1,100 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+// Selects existing log entries either by ID or by date and, optionally, assembly
+type entrySelector struct {
+	id       string
+	date     time.Time
+	assembly string
+}
+
+type entrySelectorFlags struct {
+	id       *string
+	date     *string
+	assembly *string
+}
+
+func defineEntrySelectorFlags(flags *flag.FlagSet) *entrySelectorFlags {
+	return &entrySelectorFlags{
+		id:       flags.String("id", "", "ID of the log entry, e.g., 2006-Jan-02-2; overrides 'date' and 'assembly'"),
+		date:     flags.String("date", "today", "Date of work. Supported forms: "+strings.Join(buildlog.ValidDateFormats(), ", ")),
+		assembly: flags.String("assembly", "", "Top-level assembly, to choose between several log entries on the same date"),
+	}
+}
+
+func (f *entrySelectorFlags) parse(root string) (*entrySelector, error) {
+	sel := &entrySelector{id: *f.id}
+	if len(sel.id) > 0 {
+		return sel, nil
+	}
+	if d, err := buildlog.ParseDateArg(*f.date); err != nil {
+		return nil, err
+	} else {
+		sel.date = d
+	}
+	if len(*f.assembly) > 0 {
+		config, err := buildlog.ReadProjectConfig(root)
+		if err != nil {
+			return nil, err
+		}
+		if a, err := buildlog.ParseAssemblyArg(config, *f.assembly); err != nil {
+			return nil, err
+		} else {
+			sel.assembly = a
+		}
+	}
+	return sel, nil
+}
+
+func (sel *entrySelector) String() string {
+	if len(sel.id) > 0 {
+		return sel.id
+	}
+	if len(sel.assembly) > 0 {
+		return fmt.Sprintf("%s on %s", sel.assembly, sel.date.Format(humanReadableDate))
+	}
+	return sel.date.Format(humanReadableDate)
+}
+
+// Returns the indices of all selected log entries, or an error if there are none
+func (sel *entrySelector) findAll(logs []*protos.BuildLogEntry) ([]int, error) {
+	var indices []int
+	if len(sel.id) > 0 {
+		if exists, index := buildlog.FindLogEntryByID(logs, sel.id); exists {
+			indices = append(indices, index)
+		}
+	} else {
+		indices = buildlog.FindLogEntries(logs, sel.date, sel.assembly)
+	}
+	if len(indices) == 0 {
+		return nil, cli.NewError(errorCodeNotFound, fmt.Errorf("No log entry found for %s. Create a log entry using 'log' or 'start'.", sel))
+	}
+	return indices, nil
+}
+
+// Returns the index of the selected log entry, or an error if there is not exactly one
+func (sel *entrySelector) find(logs []*protos.BuildLogEntry) (int, error) {
+	indices, err := sel.findAll(logs)
+	if err != nil {
+		return -1, err
+	}
+	if len(indices) > 1 {
+		var choices []string
+		for _, i := range indices {
+			choices = append(choices, fmt.Sprintf("%s  (%s)", buildlog.LogEntryID(logs[i]), logs[i].Assembly))
+		}
+		return -1, cli.NewError(errorCodeAmbiguous, errors.New(fmt.Sprintf("Several log entries found for %s, specify 'id' or 'assembly':\n  %s", sel, strings.Join(choices, "\n  "))))
+	}
+	return indices[0], nil
+}
@@ -0,0 +1,83 @@
+package cmds
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var SiteCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Generate a static website of the build log",
+	},
+	parseSite,
+	executeSite)
+
+type siteArgs struct {
+	root     string
+	outDir   string
+	title    string
+	baseURL  string
+	themeDir string
+}
+
+func parseSite(name string, argv []string) (*siteArgs, error) {
+	args := &siteArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	outDir := flags.String("o", "", "Output directory; defaults to site/ in the project directory")
+	title := flags.String("title", "Build Log", "Title of the site")
+	baseURL := flags.String("url", "", "URL at which the site is published, used for absolute links in the feed")
+	themeDir := flags.String("theme", "", "Directory of templates overriding the built-in templates of the same name, and an assets/ directory copied over the built-in assets")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	args.outDir = *outDir
+	if len(args.outDir) == 0 {
+		args.outDir = filepath.Join(root, "site")
+	}
+	args.title = *title
+	args.baseURL = *baseURL
+	args.themeDir = *themeDir
+	return args, nil
+}
+
+type siteResult struct {
+	Files  int    `json:"files"`
+	OutDir string `json:"out_dir"`
+}
+
+func (r *siteResult) PrintText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "Generated %d files:  %s\n", r.Files, r.OutDir)
+	return err
+}
+
+func executeSite(args *siteArgs) (cli.Result, error) {
+	tmpl, err := buildlog.LoadSiteTemplates(args.themeDir)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+	if err != nil {
+		return nil, err
+	}
+	site, err := buildlog.NewSite(args.root, logs.LogEntry, args.title, args.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	count, err := buildlog.WriteSite(args.root, args.outDir, tmpl, site, args.themeDir)
+	if err != nil {
+		return nil, err
+	}
+	return &siteResult{Files: count, OutDir: args.outDir}, nil
+}
@@ -0,0 +1,107 @@
+package cmds
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+)
+
+var SyncCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Commit uncommitted changes to the logs and details files to git",
+	},
+	parseSync,
+	executeSync)
+
+type syncArgs struct {
+	root    string
+	message string
+	dryRun  bool
+}
+
+func parseSync(name string, argv []string) (*syncArgs, error) {
+	args := &syncArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	message := flags.String("m", "", "Commit message; generated from the log entries changed if not set")
+	dryRun := flags.Bool("n", false, "List the files that would be committed without committing them")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("Unexpected argument \"%s\"", flags.Arg(0))
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	args.root = root
+	args.message = *message
+	args.dryRun = *dryRun
+	return args, nil
+}
+
+type syncResult struct {
+	Files     []string `json:"files"`
+	Message   string   `json:"message,omitempty"`
+	Committed bool     `json:"committed"`
+}
+
+func (r *syncResult) PrintText(w io.Writer) error {
+	if len(r.Files) == 0 {
+		_, err := fmt.Fprintln(w, "No uncommitted changes to the logs")
+		return err
+	}
+	if !r.Committed {
+		for _, p := range r.Files {
+			fmt.Fprintln(w, p)
+		}
+		_, err := fmt.Fprintf(w, "\n%s\n", r.Message)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Committed %d file(s)\n", len(r.Files))
+	return err
+}
+
+func executeSync(args *syncArgs) (cli.Result, error) {
+	if !args.dryRun {
+		if _, err := buildlog.EnsureLogsGitignore(args.root); err != nil {
+			return nil, err
+		}
+	}
+	files, err := buildlog.GitPendingLogFiles(args.root)
+	if err != nil {
+		return nil, err
+	}
+	result := &syncResult{Files: []string{}}
+	if len(files) == 0 {
+		return result, nil
+	}
+	for _, f := range files {
+		result.Files = append(result.Files, filepath.Join(buildlog.LogsDir(args.root), filepath.FromSlash(f)))
+	}
+	result.Message = args.message
+	if len(result.Message) == 0 {
+		logs, err := buildlog.ReadLogs(buildlog.LogsPath(args.root))
+		if err != nil {
+			return nil, err
+		}
+		result.Message = buildlog.SyncCommitMessage(logs.LogEntry, files)
+	}
+	if args.dryRun {
+		return result, nil
+	}
+	if committed, err := buildlog.GitCommit(args.root, result.Files, result.Message); err != nil {
+		return nil, err
+	} else if !committed {
+		return nil, errors.New("No changes were committed")
+	}
+	result.Committed = true
+	return result, nil
+}
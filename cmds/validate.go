@@ -0,0 +1,149 @@
+package cmds
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cragcraig/ccub/buildlog"
+	"github.com/cragcraig/ccub/cli"
+	"github.com/cragcraig/ccub/protos"
+)
+
+var ValidateCmd = cli.ConstructCommand(
+	cli.CommandMetadata{
+		Description: "Check the log file and details files for consistency",
+	},
+	parseValidate,
+	executeValidate)
+
+type validateArgs struct {
+	fix bool
+}
+
+func parseValidate(name string, argv []string) (*validateArgs, error) {
+	args := &validateArgs{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	// Raw flags
+	fix := flags.Bool("fix", false, "Repair problems that can be safely fixed automatically")
+	// Parse
+	if err := flags.Parse(argv); err != nil {
+		return nil, err
+	}
+	args.fix = *fix
+	return args, nil
+}
+
+type problemResult struct {
+	// File and line of the problem, e.g., log/buildlog.textproto:12
+	Location string `json:"location"`
+	// ID of the log entry, if the problem concerns one
+	Entry   string `json:"entry,omitempty"`
+	Message string `json:"message"`
+	Fixable bool   `json:"fixable"`
+}
+
+type validateResult struct {
+	LogsFile string          `json:"logs_file"`
+	Entries  int             `json:"entries"`
+	Fixed    *int            `json:"fixed,omitempty"`
+	Problems []problemResult `json:"problems"`
+}
+
+func (r *validateResult) PrintText(w io.Writer) error {
+	if r.Fixed != nil {
+		fmt.Fprintf(w, "Fixed %d problems\n\n", *r.Fixed)
+	}
+	for _, p := range r.Problems {
+		location := p.Location
+		if len(p.Entry) > 0 {
+			location += ": " + p.Entry
+		}
+		msg := fmt.Sprintf("%s: %s", location, p.Message)
+		if p.Fixable {
+			msg += " (fixable)"
+		}
+		fmt.Fprintln(w, msg)
+	}
+	if len(r.Problems) == 0 {
+		fmt.Fprintf(w, "%s:  %d log entries, no problems found\n", r.LogsFile, r.Entries)
+	} else {
+		// Followed by the count of problems, as the error
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func newProblemResult(f string, entryLines []int, periodLines [][]int, logs []*protos.BuildLogEntry, p *buildlog.Problem) problemResult {
+	r := problemResult{Location: f, Message: p.Message, Fixable: p.Fixable()}
+	if len(p.File) > 0 {
+		r.Location = p.File
+	} else if p.EntryIndex >= 0 && p.EntryIndex < len(entryLines) {
+		line := entryLines[p.EntryIndex]
+		if p.PeriodIndex >= 0 && p.PeriodIndex < len(periodLines[p.EntryIndex]) {
+			line = periodLines[p.EntryIndex][p.PeriodIndex]
+		}
+		r.Location = fmt.Sprintf("%s:%d", f, line)
+	}
+	if p.EntryIndex >= 0 {
+		r.Entry = logs[p.EntryIndex].Date
+	}
+	return r
+}
+
+func executeValidate(args *validateArgs) (cli.Result, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	config, err := buildlog.ReadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	f := buildlog.LogsPath(root)
+	result := &validateResult{LogsFile: f, Problems: []problemResult{}}
+
+	// Fix, then report any remaining problems
+	if args.fix {
+		fixed := 0
+		update := func(logs []*protos.BuildLogEntry) ([]*protos.BuildLogEntry, error) {
+			for _, p := range buildlog.ValidateLogs(root, logs, config, time.Now()) {
+				if p.Fixable() {
+					if err := p.Fix(); err != nil {
+						return nil, err
+					}
+					fixed++
+				}
+			}
+			return logs, nil
+		}
+		if err := buildlog.UpdateLogMetadataFile(f, update); err != nil {
+			return nil, err
+		}
+		result.Fixed = &fixed
+	}
+
+	logs, err := buildlog.ReadLogs(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", f, err.Error())
+	}
+	result.Entries = len(logs.LogEntry)
+	// Problems are located by line only in logs stored as text
+	var entryLines []int
+	var periodLines [][]int
+	if buildlog.LogStorageOfFile(f).Name() == buildlog.DefaultStorage {
+		text, err := buildlog.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		entryLines, periodLines = buildlog.LogEntryLines(text)
+	}
+	for _, p := range buildlog.ValidateLogs(root, logs.LogEntry, config, time.Now()) {
+		result.Problems = append(result.Problems, newProblemResult(f, entryLines, periodLines, logs.LogEntry, p))
+	}
+	if len(result.Problems) > 0 {
+		return result, cli.NewError(errorCodeProblems, fmt.Errorf("%d problems found", len(result.Problems)))
+	}
+	return result, nil
+}
@@ -4,5 +4,6 @@ go 1.18
 
 require (
 	github.com/golang/protobuf v1.5.0
+	github.com/yuin/goldmark v1.5.4
 	google.golang.org/protobuf v1.26.0
 )
@@ -30,6 +30,20 @@ type TimePeriod struct {
 	// e.g., 5:00PM
 	EndTime     string `protobuf:"bytes,2,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
 	DurationMin uint32 `protobuf:"varint,3,opt,name=duration_min,json=durationMin,proto3" json:"duration_min,omitempty"`
+	// RFC 3339 timestamps, e.g., 2022-05-08T13:15:00-06:00
+	Start string `protobuf:"bytes,4,opt,name=start,proto3" json:"start,omitempty"`
+	End   string `protobuf:"bytes,5,opt,name=end,proto3" json:"end,omitempty"`
+	// IANA time zone in which the work was performed, e.g., America/Denver
+	TimeZone string `protobuf:"bytes,6,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	// Work was paused rather than stopped at the end of this work period, such that the next work period continues
+	// the same session
+	Paused bool `protobuf:"varint,7,opt,name=paused,proto3" json:"paused,omitempty"`
+	// Note about the break following a paused work period, e.g., lunch
+	BreakNote string `protobuf:"bytes,8,opt,name=break_note,json=breakNote,proto3" json:"break_note,omitempty"`
+	// Name of the builder who did the work, as listed in ProjectConfig.builder
+	Builder string `protobuf:"bytes,9,opt,name=builder,proto3" json:"builder,omitempty"`
+	// Others who took part in the work, e.g., helpers or students, who are not credited with it
+	Helper []string `protobuf:"bytes,10,rep,name=helper,proto3" json:"helper,omitempty"`
 }
 
 func (x *TimePeriod) Reset() {
@@ -85,6 +99,55 @@ func (x *TimePeriod) GetDurationMin() uint32 {
 	return 0
 }
 
+func (x *TimePeriod) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *TimePeriod) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *TimePeriod) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *TimePeriod) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *TimePeriod) GetBreakNote() string {
+	if x != nil {
+		return x.BreakNote
+	}
+	return ""
+}
+
+func (x *TimePeriod) GetBuilder() string {
+	if x != nil {
+		return x.Builder
+	}
+	return ""
+}
+
+func (x *TimePeriod) GetHelper() []string {
+	if x != nil {
+		return x.Helper
+	}
+	return nil
+}
+
 type BuildLogEntry struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -101,6 +164,10 @@ type BuildLogEntry struct {
 	WorkPeriod  []*TimePeriod `protobuf:"bytes,5,rep,name=work_period,json=workPeriod,proto3" json:"work_period,omitempty"`
 	DetailsFile string        `protobuf:"bytes,6,opt,name=details_file,json=detailsFile,proto3" json:"details_file,omitempty"`
 	Tags        []string      `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Stable identifier, unique across all log entries, e.g., 2006-Jan-02 or 2006-Jan-02-2 for the second log
+	// entry of that day
+	Id         string        `protobuf:"bytes,8,opt,name=id,proto3" json:"id,omitempty"`
+	Attachment []*Attachment `protobuf:"bytes,9,rep,name=attachment,proto3" json:"attachment,omitempty"`
 }
 
 func (x *BuildLogEntry) Reset() {
@@ -184,6 +251,96 @@ func (x *BuildLogEntry) GetTags() []string {
 	return nil
 }
 
+func (x *BuildLogEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BuildLogEntry) GetAttachment() []*Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+// A photo or other file stored alongside the details file of a log entry
+type Attachment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Path relative to the logs dir, e.g., 2006-Jan/IMG_1234.jpg
+	File string `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	// Hex-encoded SHA-256 checksum of the file contents
+	Sha256 string `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	// Single line of caption text
+	Caption string `protobuf:"bytes,3,opt,name=caption,proto3" json:"caption,omitempty"`
+	// RFC 3339 timestamp at which the photo was taken, from its EXIF metadata
+	Captured string `protobuf:"bytes,4,opt,name=captured,proto3" json:"captured,omitempty"`
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Attachment) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Attachment) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Attachment) GetCaption() string {
+	if x != nil {
+		return x.Caption
+	}
+	return ""
+}
+
+func (x *Attachment) GetCaptured() string {
+	if x != nil {
+		return x.Captured
+	}
+	return ""
+}
+
 type BuildLogs struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -195,7 +352,7 @@ type BuildLogs struct {
 func (x *BuildLogs) Reset() {
 	*x = BuildLogs{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_protos_protos_proto_msgTypes[2]
+		mi := &file_protos_protos_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -208,7 +365,7 @@ func (x *BuildLogs) String() string {
 func (*BuildLogs) ProtoMessage() {}
 
 func (x *BuildLogs) ProtoReflect() protoreflect.Message {
-	mi := &file_protos_protos_proto_msgTypes[2]
+	mi := &file_protos_protos_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -221,7 +378,7 @@ func (x *BuildLogs) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BuildLogs.ProtoReflect.Descriptor instead.
 func (*BuildLogs) Descriptor() ([]byte, []int) {
-	return file_protos_protos_proto_rawDescGZIP(), []int{2}
+	return file_protos_protos_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *BuildLogs) GetLogEntry() []*BuildLogEntry {
@@ -231,102 +388,1072 @@ func (x *BuildLogs) GetLogEntry() []*BuildLogEntry {
 	return nil
 }
 
-var File_protos_protos_proto protoreflect.FileDescriptor
+type Subassembly struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_protos_protos_proto_rawDesc = []byte{
-	0x0a, 0x13, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62,
-	0x22, 0x69, 0x0a, 0x0a, 0x54, 0x69, 0x6d, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x1d,
-	0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19, 0x0a,
-	0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x75, 0x72, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b,
-	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6e, 0x22, 0xe6, 0x01, 0x0a, 0x0d,
-	0x42, 0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a,
-	0x08, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x08, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x75, 0x62,
-	0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b,
-	0x73, 0x75, 0x62, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64,
-	0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12,
-	0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x70, 0x65,
-	0x72, 0x69, 0x6f, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x61, 0x72,
-	0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f,
-	0x64, 0x52, 0x0a, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x21, 0x0a,
-	0x0c, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x46, 0x69, 0x6c, 0x65,
-	0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04,
-	0x74, 0x61, 0x67, 0x73, 0x22, 0x42, 0x0a, 0x09, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67,
-	0x73, 0x12, 0x35, 0x0a, 0x09, 0x6c, 0x6f, 0x67, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62,
-	0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08,
-	0x6c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x72, 0x61, 0x67, 0x63, 0x72, 0x61, 0x69, 0x67,
-	0x2f, 0x63, 0x63, 0x75, 0x62, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
+	// e.g., "center ribs"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Alternative names accepted on the command line, e.g., "cr"
+	Alias []string `protobuf:"bytes,2,rep,name=alias,proto3" json:"alias,omitempty"`
 }
 
-var (
-	file_protos_protos_proto_rawDescOnce sync.Once
-	file_protos_protos_proto_rawDescData = file_protos_protos_proto_rawDesc
-)
+func (x *Subassembly) Reset() {
+	*x = Subassembly{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_protos_protos_proto_rawDescGZIP() []byte {
-	file_protos_protos_proto_rawDescOnce.Do(func() {
-		file_protos_protos_proto_rawDescData = protoimpl.X.CompressGZIP(file_protos_protos_proto_rawDescData)
-	})
-	return file_protos_protos_proto_rawDescData
+func (x *Subassembly) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_protos_protos_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
-var file_protos_protos_proto_goTypes = []interface{}{
-	(*TimePeriod)(nil),    // 0: carboncub.TimePeriod
-	(*BuildLogEntry)(nil), // 1: carboncub.BuildLogEntry
-	(*BuildLogs)(nil),     // 2: carboncub.BuildLogs
+func (*Subassembly) ProtoMessage() {}
+
+func (x *Subassembly) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_protos_protos_proto_depIdxs = []int32{
-	0, // 0: carboncub.BuildLogEntry.work_period:type_name -> carboncub.TimePeriod
-	1, // 1: carboncub.BuildLogs.log_entry:type_name -> carboncub.BuildLogEntry
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+
+// Deprecated: Use Subassembly.ProtoReflect.Descriptor instead.
+func (*Subassembly) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{4}
 }
 
-func init() { file_protos_protos_proto_init() }
-func file_protos_protos_proto_init() {
-	if File_protos_protos_proto != nil {
-		return
+func (x *Subassembly) GetName() string {
+	if x != nil {
+		return x.Name
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_protos_protos_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TimePeriod); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return ""
+}
+
+func (x *Subassembly) GetAlias() []string {
+	if x != nil {
+		return x.Alias
+	}
+	return nil
+}
+
+type Assembly struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// e.g., "left wing"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Alternative names accepted on the command line, e.g., "lw"
+	Alias       []string       `protobuf:"bytes,2,rep,name=alias,proto3" json:"alias,omitempty"`
+	Subassembly []*Subassembly `protobuf:"bytes,3,rep,name=subassembly,proto3" json:"subassembly,omitempty"`
+}
+
+func (x *Assembly) Reset() {
+	*x = Assembly{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Assembly) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Assembly) ProtoMessage() {}
+
+func (x *Assembly) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_protos_protos_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BuildLogEntry); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Assembly.ProtoReflect.Descriptor instead.
+func (*Assembly) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Assembly) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Assembly) GetAlias() []string {
+	if x != nil {
+		return x.Alias
+	}
+	return nil
+}
+
+func (x *Assembly) GetSubassembly() []*Subassembly {
+	if x != nil {
+		return x.Subassembly
+	}
+	return nil
+}
+
+// A person who works on the build
+type Builder struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Alternative names accepted on the command line, e.g., initials
+	Alias []string `protobuf:"bytes,2,rep,name=alias,proto3" json:"alias,omitempty"`
+}
+
+func (x *Builder) Reset() {
+	*x = Builder{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Builder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Builder) ProtoMessage() {}
+
+func (x *Builder) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_protos_protos_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BuildLogs); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Builder.ProtoReflect.Descriptor instead.
+func (*Builder) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Builder) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Builder) GetAlias() []string {
+	if x != nil {
+		return x.Alias
+	}
+	return nil
+}
+
+// Per-project configuration, stored as ccub.textproto alongside the log directory
+type ProjectConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Assembly []*Assembly `protobuf:"bytes,1,rep,name=assembly,proto3" json:"assembly,omitempty"`
+	// Commit changes to the logs made by each command to the enclosing git repo
+	GitAutoCommit bool `protobuf:"varint,2,opt,name=git_auto_commit,json=gitAutoCommit,proto3" json:"git_auto_commit,omitempty"`
+	// Everyone who works on the build, to whom work periods are attributed
+	Builder []*Builder `protobuf:"bytes,3,rep,name=builder,proto3" json:"builder,omitempty"`
+	// Builder to whom work is attributed unless otherwise specified, e.g., by -builder or CCUB_BUILDER
+	DefaultBuilder string `protobuf:"bytes,4,opt,name=default_builder,json=defaultBuilder,proto3" json:"default_builder,omitempty"`
+	// Format in which the logs are stored: textproto (the default), json or binary. Change it with 'ccub convert'.
+	Storage string `protobuf:"bytes,5,opt,name=storage,proto3" json:"storage,omitempty"`
+}
+
+func (x *ProjectConfig) Reset() {
+	*x = ProjectConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProjectConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectConfig) ProtoMessage() {}
+
+func (x *ProjectConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectConfig.ProtoReflect.Descriptor instead.
+func (*ProjectConfig) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProjectConfig) GetAssembly() []*Assembly {
+	if x != nil {
+		return x.Assembly
+	}
+	return nil
+}
+
+func (x *ProjectConfig) GetGitAutoCommit() bool {
+	if x != nil {
+		return x.GitAutoCommit
+	}
+	return false
+}
+
+func (x *ProjectConfig) GetBuilder() []*Builder {
+	if x != nil {
+		return x.Builder
+	}
+	return nil
+}
+
+func (x *ProjectConfig) GetDefaultBuilder() string {
+	if x != nil {
+		return x.DefaultBuilder
+	}
+	return ""
+}
+
+func (x *ProjectConfig) GetStorage() string {
+	if x != nil {
+		return x.Storage
+	}
+	return ""
+}
+
+// Tokens of a details file, cached for search
+type SearchIndexDocument struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Path relative to the logs dir, as in BuildLogEntry.details_file
+	DetailsFile string `protobuf:"bytes,1,opt,name=details_file,json=detailsFile,proto3" json:"details_file,omitempty"`
+	// Modification time and size of the details file when indexed
+	ModTimeUnixNano int64 `protobuf:"varint,2,opt,name=mod_time_unix_nano,json=modTimeUnixNano,proto3" json:"mod_time_unix_nano,omitempty"`
+	Size            int64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	// Lowercase words of the details file, in order
+	Token []string `protobuf:"bytes,4,rep,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *SearchIndexDocument) Reset() {
+	*x = SearchIndexDocument{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchIndexDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchIndexDocument) ProtoMessage() {}
+
+func (x *SearchIndexDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchIndexDocument.ProtoReflect.Descriptor instead.
+func (*SearchIndexDocument) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchIndexDocument) GetDetailsFile() string {
+	if x != nil {
+		return x.DetailsFile
+	}
+	return ""
+}
+
+func (x *SearchIndexDocument) GetModTimeUnixNano() int64 {
+	if x != nil {
+		return x.ModTimeUnixNano
+	}
+	return 0
+}
+
+func (x *SearchIndexDocument) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SearchIndexDocument) GetToken() []string {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+type SearchIndex struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Document []*SearchIndexDocument `protobuf:"bytes,1,rep,name=document,proto3" json:"document,omitempty"`
+}
+
+func (x *SearchIndex) Reset() {
+	*x = SearchIndex{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchIndex) ProtoMessage() {}
+
+func (x *SearchIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchIndex.ProtoReflect.Descriptor instead.
+func (*SearchIndex) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchIndex) GetDocument() []*SearchIndexDocument {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+// The change to a single log entry by an update to the logs
+type JournalChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Unset if the log entry was added
+	Before *BuildLogEntry `protobuf:"bytes,1,opt,name=before,proto3" json:"before,omitempty"`
+	// Unset if the log entry was removed
+	After *BuildLogEntry `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`
+}
+
+func (x *JournalChange) Reset() {
+	*x = JournalChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JournalChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JournalChange) ProtoMessage() {}
+
+func (x *JournalChange) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JournalChange.ProtoReflect.Descriptor instead.
+func (*JournalChange) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *JournalChange) GetBefore() *BuildLogEntry {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *JournalChange) GetAfter() *BuildLogEntry {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+// A single update to the logs
+type JournalEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Command line that made the update, e.g., ccub stop -time 3pm
+	Command string `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	// RFC 3339 timestamp of the update
+	Timestamp string           `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Change    []*JournalChange `protobuf:"bytes,3,rep,name=change,proto3" json:"change,omitempty"`
+	// 1-based positions within the journal of the updates reverted by this update, if it was an undo
+	Undone []uint32 `protobuf:"varint,4,rep,packed,name=undone,proto3" json:"undone,omitempty"`
+}
+
+func (x *JournalEntry) Reset() {
+	*x = JournalEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JournalEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JournalEntry) ProtoMessage() {}
+
+func (x *JournalEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JournalEntry.ProtoReflect.Descriptor instead.
+func (*JournalEntry) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *JournalEntry) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *JournalEntry) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *JournalEntry) GetChange() []*JournalChange {
+	if x != nil {
+		return x.Change
+	}
+	return nil
+}
+
+func (x *JournalEntry) GetUndone() []uint32 {
+	if x != nil {
+		return x.Undone
+	}
+	return nil
+}
+
+// History of updates to the logs, stored as journal.textproto alongside the logs and only ever appended to
+type Journal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entry []*JournalEntry `protobuf:"bytes,1,rep,name=entry,proto3" json:"entry,omitempty"`
+}
+
+func (x *Journal) Reset() {
+	*x = Journal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Journal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Journal) ProtoMessage() {}
+
+func (x *Journal) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Journal.ProtoReflect.Descriptor instead.
+func (*Journal) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Journal) GetEntry() []*JournalEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+// A builder's ongoing or paused session of work
+type Timer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Empty for work not attributed to anyone
+	Builder string `protobuf:"bytes,1,opt,name=builder,proto3" json:"builder,omitempty"`
+	// Paused rather than working
+	Paused   bool   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	Assembly string `protobuf:"bytes,3,opt,name=assembly,proto3" json:"assembly,omitempty"`
+	// Unix time at which the ongoing work period started or, if paused, at which work was paused
+	SinceUnix int64 `protobuf:"varint,4,opt,name=since_unix,json=sinceUnix,proto3" json:"since_unix,omitempty"`
+}
+
+func (x *Timer) Reset() {
+	*x = Timer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Timer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Timer) ProtoMessage() {}
+
+func (x *Timer) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Timer.ProtoReflect.Descriptor instead.
+func (*Timer) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Timer) GetBuilder() string {
+	if x != nil {
+		return x.Builder
+	}
+	return ""
+}
+
+func (x *Timer) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Timer) GetAssembly() string {
+	if x != nil {
+		return x.Assembly
+	}
+	return ""
+}
+
+func (x *Timer) GetSinceUnix() int64 {
+	if x != nil {
+		return x.SinceUnix
+	}
+	return 0
+}
+
+// Ongoing and paused sessions of work, cached for shell prompts and status bars
+type TimerCache struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Modification time and size of the logs file from which the timers were read
+	ModTimeUnixNano int64    `protobuf:"varint,1,opt,name=mod_time_unix_nano,json=modTimeUnixNano,proto3" json:"mod_time_unix_nano,omitempty"`
+	Size            int64    `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Timer           []*Timer `protobuf:"bytes,3,rep,name=timer,proto3" json:"timer,omitempty"`
+}
+
+func (x *TimerCache) Reset() {
+	*x = TimerCache{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_protos_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TimerCache) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimerCache) ProtoMessage() {}
+
+func (x *TimerCache) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_protos_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimerCache.ProtoReflect.Descriptor instead.
+func (*TimerCache) Descriptor() ([]byte, []int) {
+	return file_protos_protos_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TimerCache) GetModTimeUnixNano() int64 {
+	if x != nil {
+		return x.ModTimeUnixNano
+	}
+	return 0
+}
+
+func (x *TimerCache) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *TimerCache) GetTimer() []*Timer {
+	if x != nil {
+		return x.Timer
+	}
+	return nil
+}
+
+var File_protos_protos_proto protoreflect.FileDescriptor
+
+var file_protos_protos_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62,
+	0x22, 0x97, 0x02, 0x0a, 0x0a, 0x54, 0x69, 0x6d, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x19,
+	0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x65, 0x6e, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x7a, 0x6f, 0x6e,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x5a, 0x6f, 0x6e,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x72, 0x65,
+	0x61, 0x6b, 0x5f, 0x6e, 0x6f, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62,
+	0x72, 0x65, 0x61, 0x6b, 0x4e, 0x6f, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x75, 0x69, 0x6c,
+	0x64, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x6c, 0x70, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x6c, 0x70, 0x65, 0x72, 0x22, 0xad, 0x02, 0x0a, 0x0d, 0x42,
+	0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08,
+	0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x75, 0x62, 0x61,
+	0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x73,
+	0x75, 0x62, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x70, 0x65, 0x72,
+	0x69, 0x6f, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x61, 0x72, 0x62,
+	0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64,
+	0x52, 0x0a, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x21, 0x0a, 0x0c,
+	0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x35, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e,
+	0x74, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e,
+	0x63, 0x75, 0x62, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0a,
+	0x61, 0x74, 0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x6e, 0x0a, 0x0a, 0x41, 0x74,
+	0x74, 0x61, 0x63, 0x68, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x68,
+	0x61, 0x32, 0x35, 0x36, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x61, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x61, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a,
+	0x0a, 0x08, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x64, 0x22, 0x42, 0x0a, 0x09, 0x42, 0x75,
+	0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x35, 0x0a, 0x09, 0x6c, 0x6f, 0x67, 0x5f, 0x65,
+	0x6e, 0x74, 0x72, 0x79, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x61, 0x72,
+	0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x37,
+	0x0a, 0x0b, 0x53, 0x75, 0x62, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0x6e, 0x0a, 0x08, 0x41, 0x73, 0x73, 0x65, 0x6d,
+	0x62, 0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x38, 0x0a,
+	0x0b, 0x73, 0x75, 0x62, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x53,
+	0x75, 0x62, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x61,
+	0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x22, 0x33, 0x0a, 0x07, 0x42, 0x75, 0x69, 0x6c, 0x64,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0xd9, 0x01, 0x0a,
+	0x0d, 0x50, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2f,
+	0x0a, 0x08, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x41, 0x73, 0x73,
+	0x65, 0x6d, 0x62, 0x6c, 0x79, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12,
+	0x26, 0x0a, 0x0f, 0x67, 0x69, 0x74, 0x5f, 0x61, 0x75, 0x74, 0x6f, 0x5f, 0x63, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x67, 0x69, 0x74, 0x41, 0x75, 0x74,
+	0x6f, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x2c, 0x0a, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64,
+	0x65, 0x72, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f,
+	0x6e, 0x63, 0x75, 0x62, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x52, 0x07, 0x62, 0x75,
+	0x69, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74,
+	0x5f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x22, 0x8f, 0x01, 0x0a, 0x13, 0x53, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x5f, 0x66, 0x69, 0x6c, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x46,
+	0x69, 0x6c, 0x65, 0x12, 0x2b, 0x0a, 0x12, 0x6d, 0x6f, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f,
+	0x75, 0x6e, 0x69, 0x78, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0f, 0x6d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4e, 0x61, 0x6e, 0x6f,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04,
+	0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x49, 0x0a, 0x0b, 0x53, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x3a, 0x0a, 0x08, 0x64, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x61,
+	0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x64, 0x6f, 0x63,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x71, 0x0a, 0x0d, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63,
+	0x75, 0x62, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e,
+	0x63, 0x75, 0x62, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x64, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x22, 0x90, 0x01, 0x0a, 0x0c, 0x4a, 0x6f, 0x75,
+	0x72, 0x6e, 0x61, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x30, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x4a, 0x6f,
+	0x75, 0x72, 0x6e, 0x61, 0x6c, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x06, 0x63, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x64, 0x6f, 0x6e, 0x65, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0d, 0x52, 0x06, 0x75, 0x6e, 0x64, 0x6f, 0x6e, 0x65, 0x22, 0x38, 0x0a, 0x07, 0x4a,
+	0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x12, 0x2d, 0x0a, 0x05, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x61, 0x72, 0x62, 0x6f, 0x6e, 0x63, 0x75,
+	0x62, 0x2e, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05,
+	0x65, 0x6e, 0x74, 0x72, 0x79, 0x22, 0x74, 0x0a, 0x05, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x12, 0x18,
+	0x0a, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64,
+	0x12, 0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65, 0x6d, 0x62, 0x6c, 0x79, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x22, 0x75, 0x0a, 0x0a, 0x54,
+	0x69, 0x6d, 0x65, 0x72, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x2b, 0x0a, 0x12, 0x6d, 0x6f, 0x64,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x55, 0x6e,
+	0x69, 0x78, 0x4e, 0x61, 0x6e, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x26, 0x0a, 0x05, 0x74, 0x69,
+	0x6d, 0x65, 0x72, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x61, 0x72, 0x62,
+	0x6f, 0x6e, 0x63, 0x75, 0x62, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x72, 0x52, 0x05, 0x74, 0x69, 0x6d,
+	0x65, 0x72, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x63, 0x72, 0x61, 0x67, 0x63, 0x72, 0x61, 0x69, 0x67, 0x2f, 0x63, 0x63, 0x75, 0x62, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_protos_protos_proto_rawDescOnce sync.Once
+	file_protos_protos_proto_rawDescData = file_protos_protos_proto_rawDesc
+)
+
+func file_protos_protos_proto_rawDescGZIP() []byte {
+	file_protos_protos_proto_rawDescOnce.Do(func() {
+		file_protos_protos_proto_rawDescData = protoimpl.X.CompressGZIP(file_protos_protos_proto_rawDescData)
+	})
+	return file_protos_protos_proto_rawDescData
+}
+
+var file_protos_protos_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_protos_protos_proto_goTypes = []interface{}{
+	(*TimePeriod)(nil),          // 0: carboncub.TimePeriod
+	(*BuildLogEntry)(nil),       // 1: carboncub.BuildLogEntry
+	(*Attachment)(nil),          // 2: carboncub.Attachment
+	(*BuildLogs)(nil),           // 3: carboncub.BuildLogs
+	(*Subassembly)(nil),         // 4: carboncub.Subassembly
+	(*Assembly)(nil),            // 5: carboncub.Assembly
+	(*Builder)(nil),             // 6: carboncub.Builder
+	(*ProjectConfig)(nil),       // 7: carboncub.ProjectConfig
+	(*SearchIndexDocument)(nil), // 8: carboncub.SearchIndexDocument
+	(*SearchIndex)(nil),         // 9: carboncub.SearchIndex
+	(*JournalChange)(nil),       // 10: carboncub.JournalChange
+	(*JournalEntry)(nil),        // 11: carboncub.JournalEntry
+	(*Journal)(nil),             // 12: carboncub.Journal
+	(*Timer)(nil),               // 13: carboncub.Timer
+	(*TimerCache)(nil),          // 14: carboncub.TimerCache
+}
+var file_protos_protos_proto_depIdxs = []int32{
+	0,  // 0: carboncub.BuildLogEntry.work_period:type_name -> carboncub.TimePeriod
+	2,  // 1: carboncub.BuildLogEntry.attachment:type_name -> carboncub.Attachment
+	1,  // 2: carboncub.BuildLogs.log_entry:type_name -> carboncub.BuildLogEntry
+	4,  // 3: carboncub.Assembly.subassembly:type_name -> carboncub.Subassembly
+	5,  // 4: carboncub.ProjectConfig.assembly:type_name -> carboncub.Assembly
+	6,  // 5: carboncub.ProjectConfig.builder:type_name -> carboncub.Builder
+	8,  // 6: carboncub.SearchIndex.document:type_name -> carboncub.SearchIndexDocument
+	1,  // 7: carboncub.JournalChange.before:type_name -> carboncub.BuildLogEntry
+	1,  // 8: carboncub.JournalChange.after:type_name -> carboncub.BuildLogEntry
+	10, // 9: carboncub.JournalEntry.change:type_name -> carboncub.JournalChange
+	11, // 10: carboncub.Journal.entry:type_name -> carboncub.JournalEntry
+	13, // 11: carboncub.TimerCache.timer:type_name -> carboncub.Timer
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_protos_protos_proto_init() }
+func file_protos_protos_proto_init() {
+	if File_protos_protos_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_protos_protos_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TimePeriod); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuildLogEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Attachment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuildLogs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Subassembly); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Assembly); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Builder); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProjectConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchIndexDocument); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchIndex); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JournalChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JournalEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Journal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Timer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_protos_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TimerCache); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -344,7 +1471,7 @@ func file_protos_protos_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protos_protos_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   0,
 		},